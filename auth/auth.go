@@ -0,0 +1,286 @@
+// Package auth implements the OAuth2 authorization code flow of Mercedes ID,
+// the authorization server of the Mercedes API.
+//
+// The flow is protected with PKCE and a state parameter. The tokens it
+// returns are used to build an http.Client ready to be passed to
+// merche.NewClient:
+//
+//	cfg := &auth.Config{
+//		ClientID:     "...",
+//		ClientSecret: "...",
+//		RedirectURL:  "http://localhost:3000/login/mercedes/callback",
+//		Scopes:       []string{"mb:vehicle:mbdata:vehiclestatus", "offline_access"},
+//	}
+//
+//	authz, err := cfg.NewAuthorization()
+//	// Redirect the user to authz.URL and keep authz until the callback.
+//
+//	tok, err := cfg.Exchange(ctx, authz, callbackURL.Query())
+//	client := merche.NewClient(cfg.Client(tok))
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Endpoint holds the URLs of an OAuth2 authorization server.
+type Endpoint struct {
+	AuthURL  string
+	TokenURL string
+}
+
+// MercedesEndpoint is the Mercedes ID authorization server.
+var MercedesEndpoint = Endpoint{
+	AuthURL:  "https://id.mercedes-benz.com/as/authorization.oauth2",
+	TokenURL: "https://id.mercedes-benz.com/as/token.oauth2",
+}
+
+var (
+	// ErrStateMismatch is returned when the state of an authorization
+	// callback does not match the state of the authorization request.
+	ErrStateMismatch = errors.New("auth: state mismatch")
+
+	// ErrNoRefreshToken is returned when a token cannot be refreshed
+	// because it has no refresh token.
+	ErrNoRefreshToken = errors.New("auth: no refresh token")
+)
+
+// Config describes an application registered in the Mercedes developer
+// portal.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Endpoint is the authorization server. Defaults to MercedesEndpoint.
+	Endpoint Endpoint
+
+	// HTTPClient is used to send token requests. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+func (c *Config) endpoint() Endpoint {
+	if c.Endpoint == (Endpoint{}) {
+		return MercedesEndpoint
+	}
+	return c.Endpoint
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// Authorization is a pending authorization request. It must be kept, for
+// instance in the session of the user, until the authorization server
+// redirects the user back to the redirect URL.
+type Authorization struct {
+	// URL is the address the user must be redirected to.
+	URL string
+	// State is the state parameter sent to the authorization server.
+	State string
+	// CodeVerifier is the PKCE code verifier of the request.
+	CodeVerifier string
+}
+
+// NewAuthorization starts an authorization request with a random state and
+// PKCE code verifier.
+func (c *Config) NewAuthorization() (*Authorization, error) {
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := url.Parse(c.endpoint().AuthURL)
+	if err != nil {
+		return nil, fmt.Errorf("auth: invalid authorization URL: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", c.RedirectURL)
+	if len(c.Scopes) > 0 {
+		q.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+
+	return &Authorization{
+		URL:          authURL.String(),
+		State:        state,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// Exchange completes the authorization request a using the query of the
+// callback request sent by the authorization server. The state of the
+// callback is validated before the authorization code is exchanged for a
+// token.
+func (c *Config) Exchange(ctx context.Context, a *Authorization, query url.Values) (*Token, error) {
+	if code := query.Get("error"); code != "" {
+		return nil, &AuthorizationError{Code: code, Description: query.Get("error_description")}
+	}
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(a.State)) != 1 {
+		return nil, ErrStateMismatch
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, errors.New("auth: callback has no authorization code")
+	}
+
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"code_verifier": {a.CodeVerifier},
+	})
+}
+
+// Refresh gets a new token using a refresh token. If the authorization
+// server does not rotate the refresh token, the given one is kept.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	tok, err := c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+func (c *Config) retrieveToken(ctx context.Context, form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint().TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("auth: error reading token response: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode > 299 {
+		tokErr := &TokenError{StatusCode: resp.StatusCode, Body: body}
+		var errBody struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &errBody) == nil {
+			tokErr.Code = errBody.Error
+			tokErr.Description = errBody.ErrorDescription
+		}
+		return nil, tokErr
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("auth: error decoding token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("auth: token response has no access token")
+	}
+
+	return tr.token(time.Now()), nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+func (tr *tokenResponse) token(now time.Time) *Token {
+	tok := &Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Scope:        tr.Scope,
+	}
+	if tr.ExpiresIn > 0 {
+		tok.Expiry = now.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return tok
+}
+
+// AuthorizationError is returned when the authorization server redirects
+// the user back with an error, for instance because consent was denied.
+type AuthorizationError struct {
+	Code        string
+	Description string
+}
+
+func (e *AuthorizationError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("auth: authorization failed: %v", e.Code)
+	}
+	return fmt.Sprintf("auth: authorization failed: %v: %v", e.Code, e.Description)
+}
+
+// TokenError is returned when the token endpoint rejects a request.
+type TokenError struct {
+	StatusCode  int
+	Code        string
+	Description string
+	Body        []byte
+}
+
+func (e *TokenError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("auth: token request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("auth: token request failed with status %d: %v: %v", e.StatusCode, e.Code, e.Description)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("auth: error generating random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
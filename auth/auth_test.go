@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuthServer is a minimal OAuth2 authorization server supporting the
+// authorization code flow with PKCE and refresh tokens.
+type fakeAuthServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	challenges map[string]string
+	expiresIn  int64
+	refreshes  int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	s := &fakeAuthServer{challenges: make(map[string]string), expiresIn: 3600}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		s.mu.Lock()
+		s.challenges["code-"+q.Get("state")] = q.Get("code_challenge")
+		s.mu.Unlock()
+
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {"code-" + q.Get("state")}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client-id" || secret != "client-secret" {
+			tokenError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		switch r.PostFormValue("grant_type") {
+		case "authorization_code":
+			challenge, ok := s.challenges[r.PostFormValue("code")]
+			verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+			if !ok || challenge != base64.RawURLEncoding.EncodeToString(verifier[:]) {
+				tokenError(w, http.StatusBadRequest, "invalid_grant")
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "access-0",
+				"token_type":    "Bearer",
+				"refresh_token": "refresh-0",
+				"expires_in":    s.expiresIn,
+				"scope":         "mb:vehicle:mbdata:vehiclestatus offline_access",
+			})
+		case "refresh_token":
+			switch r.PostFormValue("refresh_token") {
+			case "revoked":
+				tokenError(w, http.StatusBadRequest, "invalid_grant")
+				return
+			case "unavailable":
+				tokenError(w, http.StatusServiceUnavailable, "temporarily_unavailable")
+				return
+			}
+			s.refreshes++
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "access-refreshed",
+				"token_type":   "Bearer",
+				"expires_in":   s.expiresIn,
+			})
+		default:
+			tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		}
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": code + " description"})
+}
+
+func (s *fakeAuthServer) config() *Config {
+	return &Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:3000/callback",
+		Scopes:       []string{"mb:vehicle:mbdata:vehiclestatus", "offline_access"},
+		Endpoint: Endpoint{
+			AuthURL:  s.URL + "/authorize",
+			TokenURL: s.URL + "/token",
+		},
+		HTTPClient: s.Client(),
+	}
+}
+
+// authorize follows the authorization URL and returns the callback query.
+func (s *fakeAuthServer) authorize(t *testing.T, authz *Authorization) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authz.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	callback, _ := url.Parse(resp.Header.Get("Location"))
+	return callback.Query()
+}
+
+func TestConfig_NewAuthorization(t *testing.T) {
+	cfg := &Config{ClientID: "client-id", RedirectURL: "http://localhost/callback", Scopes: []string{"a", "b"}}
+
+	authz, err := cfg.NewAuthorization()
+	assert.NoError(t, err)
+
+	u, _ := url.Parse(authz.URL)
+	assert.Equal(t, "id.mercedes-benz.com", u.Host)
+
+	q := u.Query()
+	verifier := sha256.Sum256([]byte(authz.CodeVerifier))
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "client-id", q.Get("client_id"))
+	assert.Equal(t, "a b", q.Get("scope"))
+	assert.Equal(t, authz.State, q.Get("state"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(verifier[:]), q.Get("code_challenge"))
+
+	other, _ := cfg.NewAuthorization()
+	assert.NotEqual(t, authz.State, other.State)
+	assert.NotEqual(t, authz.CodeVerifier, other.CodeVerifier)
+}
+
+func TestConfig_Exchange(t *testing.T) {
+	server := newFakeAuthServer(t)
+	cfg := server.config()
+	ctx := context.Background()
+
+	authz, _ := cfg.NewAuthorization()
+	callback := server.authorize(t, authz)
+
+	tok, err := cfg.Exchange(ctx, authz, callback)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-0", tok.AccessToken)
+	assert.Equal(t, "refresh-0", tok.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tok.Expiry, time.Minute)
+	assert.True(t, tok.Valid())
+
+	t.Run("state mismatch", func(t *testing.T) {
+		forged := url.Values{"code": callback["code"], "state": {"forged"}}
+		_, err := cfg.Exchange(ctx, authz, forged)
+		assert.ErrorIs(t, err, ErrStateMismatch)
+	})
+
+	t.Run("authorization denied", func(t *testing.T) {
+		_, err := cfg.Exchange(ctx, authz, url.Values{"error": {"access_denied"}, "state": {authz.State}})
+		var authzErr *AuthorizationError
+		if assert.True(t, errors.As(err, &authzErr)) {
+			assert.Equal(t, "access_denied", authzErr.Code)
+		}
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		other, _ := cfg.NewAuthorization()
+		callback := server.authorize(t, other)
+		other.CodeVerifier = authz.CodeVerifier
+
+		_, err := cfg.Exchange(ctx, other, callback)
+		var tokErr *TokenError
+		if assert.True(t, errors.As(err, &tokErr)) {
+			assert.Equal(t, "invalid_grant", tokErr.Code)
+			assert.Equal(t, http.StatusBadRequest, tokErr.StatusCode)
+		}
+	})
+}
+
+func TestConfig_Refresh(t *testing.T) {
+	server := newFakeAuthServer(t)
+	cfg := server.config()
+	ctx := context.Background()
+
+	tok, err := cfg.Refresh(ctx, "refresh-0")
+	assert.NoError(t, err)
+	assert.Equal(t, "access-refreshed", tok.AccessToken)
+	assert.Equal(t, "refresh-0", tok.RefreshToken, "refresh token must be kept when not rotated")
+
+	_, err = cfg.Refresh(ctx, "")
+	assert.ErrorIs(t, err, ErrNoRefreshToken)
+
+	_, err = cfg.Refresh(ctx, "revoked")
+	assert.Error(t, err)
+}
+
+func TestConfig_Client(t *testing.T) {
+	server := newFakeAuthServer(t)
+	cfg := server.config()
+
+	expired := &Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)}
+	client := cfg.Client(expired)
+	client.Transport.(*Transport).Base = server.Client().Transport
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/api")
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "Bearer access-refreshed", string(got))
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 1, server.refreshes, "valid tokens must not be refreshed")
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoToken is returned by a TokenStore that holds no token.
+var ErrNoToken = errors.New("auth: no token stored")
+
+// TokenStore persists the token of a Mercedes me account, so that it
+// survives restarts.
+type TokenStore interface {
+	// Load returns the stored token, or ErrNoToken if there is none.
+	Load(ctx context.Context) (*Token, error)
+	// Save replaces the stored token.
+	Save(ctx context.Context, tok *Token) error
+}
+
+// MemoryStore is a TokenStore keeping the token in memory. The zero value
+// is an empty store.
+type MemoryStore struct {
+	mu  sync.Mutex
+	tok *Token
+}
+
+// NewMemoryStore returns a MemoryStore holding tok.
+func NewMemoryStore(tok *Token) *MemoryStore {
+	return &MemoryStore{tok: tok}
+}
+
+// Load implements the TokenStore interface.
+func (s *MemoryStore) Load(context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok == nil {
+		return nil, ErrNoToken
+	}
+	tok := *s.tok
+	return &tok, nil
+}
+
+// Save implements the TokenStore interface.
+func (s *MemoryStore) Save(_ context.Context, tok *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := *tok
+	s.tok = &t
+	return nil
+}
+
+// FileStore is a TokenStore keeping the token in a file readable only by
+// its owner. The file is replaced atomically on every save.
+type FileStore struct {
+	Path string
+
+	// Key, if set, encrypts the file with AES-GCM. It must be 16, 24 or 32
+	// bytes long.
+	Key []byte
+}
+
+// Load implements the TokenStore interface.
+func (s *FileStore) Load(context.Context) (*Token, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Key != nil {
+		if data, err = s.decrypt(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var tok Token
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return nil, fmt.Errorf("auth: error decoding token file: %w", err)
+	}
+	return &tok, nil
+}
+
+// Save implements the TokenStore interface.
+func (s *FileStore) Save(_ context.Context, tok *Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	if s.Key != nil {
+		if data, err = s.encrypt(data); err != nil {
+			return err
+		}
+	}
+
+	// The temporary file is created with 0600 permissions in the same
+	// directory, so that renaming it replaces the token file atomically.
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), "."+filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.Path)
+}
+
+func (s *FileStore) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("auth: invalid token file key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *FileStore) encrypt(plaintext []byte) ([]byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (s *FileStore) decrypt(ciphertext []byte) ([]byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("auth: token file is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: error decrypting token file: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	var s MemoryStore
+	_, err := s.Load(ctx)
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	tok := &Token{AccessToken: "access", RefreshToken: "refresh"}
+	assert.NoError(t, s.Save(ctx, tok))
+
+	got, err := s.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tok, got)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	tok := &Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		Expiry:       time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{name: "plain"},
+		{name: "encrypted", key: []byte("0123456789abcdef0123456789abcdef")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "token.json")
+			s := &FileStore{Path: path, Key: tt.key}
+
+			_, err := s.Load(ctx)
+			assert.ErrorIs(t, err, ErrNoToken)
+
+			assert.NoError(t, s.Save(ctx, &Token{AccessToken: "old"}))
+			assert.NoError(t, s.Save(ctx, tok))
+
+			got, err := s.Load(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tok.AccessToken, got.AccessToken)
+			assert.True(t, tok.Expiry.Equal(got.Expiry))
+
+			info, err := os.Stat(path)
+			assert.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			files, _ := ioutil.ReadDir(filepath.Dir(path))
+			assert.Len(t, files, 1, "temporary files must be removed")
+
+			data, _ := ioutil.ReadFile(path)
+			assert.Equal(t, tt.key == nil, strings.Contains(string(data), "refresh"))
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token.json")
+		assert.NoError(t, (&FileStore{Path: path, Key: make([]byte, 32)}).Save(ctx, tok))
+
+		_, err := (&FileStore{Path: path, Key: []byte("0123456789abcdef0123456789abcdef")}).Load(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		err := (&FileStore{Path: filepath.Join(t.TempDir(), "token.json"), Key: []byte("short")}).Save(ctx, tok)
+		assert.Error(t, err)
+	})
+}
+
+type failingStore struct {
+	MemoryStore
+	fail bool
+}
+
+func (s *failingStore) Save(ctx context.Context, tok *Token) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.MemoryStore.Save(ctx, tok)
+}
+
+func TestConfig_StoreTokenSource(t *testing.T) {
+	server := newFakeAuthServer(t)
+	cfg := server.config()
+	ctx := context.Background()
+
+	t.Run("refresh before expiry and persist", func(t *testing.T) {
+		store := NewMemoryStore(&Token{
+			AccessToken:  "access-0",
+			RefreshToken: "refresh-0",
+			Expiry:       time.Now().Add(30 * time.Second),
+		})
+		ts := cfg.StoreTokenSource(store, time.Minute)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tok, err := ts.Token(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, "access-refreshed", tok.AccessToken)
+			}()
+		}
+		wg.Wait()
+
+		server.mu.Lock()
+		assert.Equal(t, 1, server.refreshes)
+		server.mu.Unlock()
+
+		saved, _ := store.Load(ctx)
+		assert.Equal(t, "access-refreshed", saved.AccessToken)
+		assert.Equal(t, "refresh-0", saved.RefreshToken)
+	})
+
+	t.Run("empty store", func(t *testing.T) {
+		_, err := cfg.StoreTokenSource(&MemoryStore{}, 0).Token(ctx)
+		assert.ErrorIs(t, err, ErrNoToken)
+	})
+
+	t.Run("refresh failure", func(t *testing.T) {
+		ts := cfg.StoreTokenSource(NewMemoryStore(&Token{RefreshToken: "revoked"}), 0)
+
+		_, err := ts.Token(ctx)
+		var refreshErr *RefreshError
+		assert.True(t, errors.As(err, &refreshErr))
+
+		_, err = cfg.StoreTokenSource(NewMemoryStore(&Token{AccessToken: "expired", Expiry: time.Now().Add(-time.Minute)}), 0).Token(ctx)
+		assert.True(t, errors.As(err, &refreshErr))
+		assert.ErrorIs(t, err, ErrNoRefreshToken)
+	})
+
+	t.Run("transient refresh failure", func(t *testing.T) {
+		ts := cfg.StoreTokenSource(NewMemoryStore(&Token{RefreshToken: "unavailable"}), 0)
+
+		_, err := ts.Token(ctx)
+		var refreshErr *RefreshError
+		assert.False(t, errors.As(err, &refreshErr), "transient failures must not require a new authorization")
+		var tokErr *TokenError
+		if assert.True(t, errors.As(err, &tokErr)) {
+			assert.Equal(t, http.StatusServiceUnavailable, tokErr.StatusCode)
+		}
+	})
+
+	t.Run("save failure is retried", func(t *testing.T) {
+		store := &failingStore{fail: true}
+		store.tok = &Token{RefreshToken: "refresh-0"}
+		ts := cfg.StoreTokenSource(store, 0)
+
+		_, err := ts.Token(ctx)
+		assert.Error(t, err)
+
+		store.fail = false
+		tok, err := ts.Token(ctx)
+		assert.NoError(t, err)
+		saved, _ := store.Load(ctx)
+		assert.Equal(t, tok.AccessToken, saved.AccessToken)
+	})
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta is how long before its expiry a token is considered expired,
+// so that it does not expire while a request is in flight.
+const expiryDelta = 10 * time.Second
+
+// Token holds the credentials returned by the authorization server.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// Expiry is the expiration time of the access token. The zero value
+	// means the token does not expire.
+	Expiry time.Time `json:"expiry,omitempty"`
+	Scope  string    `json:"scope,omitempty"`
+}
+
+// Valid reports whether the token has an access token that is not expired.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && !t.expiresWithin(expiryDelta, time.Now())
+}
+
+func (t *Token) expiresWithin(d time.Duration, now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Add(d).Before(t.Expiry)
+}
+
+// Scopes returns the scopes granted to the token, ready to be passed to
+// merche.WithGrantedScopes.
+func (t *Token) Scopes() []string {
+	return strings.Fields(t.Scope)
+}
+
+// TokenSource returns the token to authorize a request with.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// StaticTokenSource returns a TokenSource that always returns tok.
+func StaticTokenSource(tok *Token) TokenSource {
+	return staticTokenSource{tok}
+}
+
+type staticTokenSource struct {
+	tok *Token
+}
+
+func (s staticTokenSource) Token(context.Context) (*Token, error) {
+	return s.tok, nil
+}
+
+// RefreshError is returned when a token cannot be refreshed because its
+// refresh token is missing or has been rejected with invalid_grant. Unlike a
+// merche.UnauthorizedError, returned by the Mercedes API for a rejected
+// access token, it means that the account must go through the
+// authorization flow again. Other refresh failures, like network errors or
+// a token endpoint unavailable, are transient and returned as they are.
+type RefreshError struct {
+	Err error
+}
+
+func (e *RefreshError) Error() string {
+	return fmt.Sprintf("auth: token refresh failed: %v", e.Err)
+}
+
+func (e *RefreshError) Unwrap() error { return e.Err }
+
+// TokenSource returns a TokenSource that returns tok until it expires and
+// then refreshes it using its refresh token. It is safe for concurrent use.
+func (c *Config) TokenSource(tok *Token) TokenSource {
+	return c.StoreTokenSource(NewMemoryStore(tok), 0)
+}
+
+// StoreTokenSource returns a TokenSource that loads the token from store and
+// refreshes it when it expires within leeway, saving the rotated token back
+// to store. A zero leeway defaults to 10 seconds.
+//
+// The returned TokenSource is safe for concurrent use: concurrent requests
+// trigger a single refresh. It must be the only one refreshing the tokens
+// of store.
+func (c *Config) StoreTokenSource(store TokenStore, leeway time.Duration) TokenSource {
+	if leeway <= 0 {
+		leeway = expiryDelta
+	}
+	return &storeTokenSource{config: c, store: store, leeway: leeway, now: time.Now}
+}
+
+type storeTokenSource struct {
+	config *Config
+	store  TokenStore
+	leeway time.Duration
+	now    func() time.Time
+
+	mu  sync.Mutex
+	tok *Token
+	// unsaved reports whether tok has been refreshed but could not be
+	// saved yet.
+	unsaved bool
+}
+
+func (s *storeTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok == nil {
+		tok, err := s.store.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.tok = tok
+	}
+	if s.unsaved {
+		if err := s.save(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if s.tok.AccessToken != "" && !s.tok.expiresWithin(s.leeway, s.now()) {
+		return s.tok, nil
+	}
+
+	tok, err := s.config.Refresh(ctx, s.tok.RefreshToken)
+	if err != nil {
+		if reauthorizationRequired(err) {
+			return nil, &RefreshError{Err: err}
+		}
+		return nil, err
+	}
+	s.tok = tok
+	s.unsaved = true
+
+	if err := s.save(ctx); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// reauthorizationRequired reports whether a refresh failed because the
+// refresh token can no longer be used.
+func reauthorizationRequired(err error) bool {
+	var tokErr *TokenError
+	return errors.Is(err, ErrNoRefreshToken) || errors.As(err, &tokErr) && tokErr.Code == "invalid_grant"
+}
+
+func (s *storeTokenSource) save(ctx context.Context) error {
+	if err := s.store.Save(ctx, s.tok); err != nil {
+		return fmt.Errorf("auth: error saving refreshed token: %w", err)
+	}
+	s.unsaved = false
+	return nil
+}
+
+// Client returns an http.Client that authorizes every request with tok,
+// refreshing it when it expires. The returned client is meant to be passed
+// to merche.NewClient.
+func (c *Config) Client(tok *Token) *http.Client {
+	return NewClient(c.TokenSource(tok))
+}
+
+// NewClient returns an http.Client that authorizes every request with the
+// tokens of ts.
+func NewClient(ts TokenSource) *http.Client {
+	return &http.Client{Transport: &Transport{Source: ts}}
+}
+
+// Transport is an http.RoundTripper that authorizes requests with a bearer
+// token.
+type Transport struct {
+	Source TokenSource
+	// Base is the RoundTripper used to send the requests. Defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	closeBody := func() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+	}
+
+	if t.Source == nil {
+		closeBody()
+		return nil, errors.New("auth: transport has no token source")
+	}
+	tok, err := t.Source.Token(req.Context())
+	if err != nil {
+		closeBody()
+		return nil, err
+	}
+
+	// The clone shares the body of req, which is closed by the base
+	// transport.
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(r)
+}
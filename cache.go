@@ -0,0 +1,237 @@
+package merche
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the number of responses kept by the storage of a
+// Cache without Storage.
+const DefaultCacheSize = 1000
+
+// CacheEntry is a response stored in a Cache.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+}
+
+// CacheStorage stores the entries of a Cache by key. It must be safe for
+// concurrent use.
+type CacheStorage interface {
+	// Get returns the entry stored with key, if any.
+	Get(key string) (*CacheEntry, bool)
+	// Set stores e with key, replacing the entry stored with it, if any.
+	Set(key string, e *CacheEntry)
+	// Delete removes the entry stored with key, if any.
+	Delete(key string)
+}
+
+// Cache caches the 200 OK responses of GET requests, keyed by method and
+// path, and by account when the Client has Credentials, so that reading the
+// same container of a vehicle several times within its TTL costs a single
+// request.
+type Cache struct {
+	// TTLs are the times to live of the responses of each container. A
+	// single resource is cached for the shortest TTL of its containers.
+	// Responses of containers without TTL are not cached.
+	TTLs map[Container]time.Duration
+	// DefaultTTL is the time to live of the responses of requests that do
+	// not address a container or a resource, like the available resources
+	// of a vehicle. Zero disables caching them.
+	DefaultTTL time.Duration
+	// Storage stores the responses. Defaults to an LRUStorage of
+	// DefaultCacheSize entries.
+	Storage CacheStorage
+	// Clock is the clock the age of the entries is measured with. Defaults
+	// to the system clock.
+	Clock Clock
+
+	once sync.Once
+}
+
+func (c *Cache) init() {
+	c.once.Do(func() {
+		if c.Storage == nil {
+			c.Storage = NewLRUStorage(DefaultCacheSize)
+		}
+		if c.Clock == nil {
+			c.Clock = systemClock{}
+		}
+	})
+}
+
+// ttl returns the time to live of the responses of req, or zero if they
+// are not cached.
+func (c *Cache) ttl(req *http.Request) time.Duration {
+	if c == nil || req.Method != http.MethodGet {
+		return 0
+	}
+
+	t := parseTarget(req.URL.Path)
+	switch {
+	case t.container != "":
+		return c.TTLs[t.container]
+	case t.resource != "":
+		var ttl time.Duration
+		for i, ct := range resourceContainers(t.resource) {
+			if d := c.TTLs[ct]; i == 0 || d < ttl {
+				ttl = d
+			}
+		}
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+func cacheKey(req *http.Request, account *Account) string {
+	key := req.Method + " " + req.URL.RequestURI()
+	if account != nil {
+		key = account.ID + " " + key
+	}
+	return key
+}
+
+// lookup returns the response cached for req, authorized by account, and
+// its age, if it has not expired.
+func (c *Cache) lookup(req *http.Request, account *Account) (*http.Response, time.Duration, bool) {
+	ttl := c.ttl(req)
+	if ttl <= 0 || refreshForced(req.Context()) {
+		return nil, 0, false
+	}
+	c.init()
+
+	key := cacheKey(req, account)
+	e, ok := c.Storage.Get(key)
+	if !ok {
+		return nil, 0, false
+	}
+	age := c.Clock.Now().Sub(e.StoredAt)
+	if age >= ttl {
+		c.Storage.Delete(key)
+		return nil, 0, false
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, age, true
+}
+
+// store caches resp, whose body is body, if it is a 200 OK response to a
+// cached request. Other responses, like 204 No Content, are not cached.
+func (c *Cache) store(req *http.Request, account *Account, resp *http.Response, body []byte) {
+	if c.ttl(req) <= 0 || resp.StatusCode != http.StatusOK {
+		return
+	}
+	c.init()
+
+	c.Storage.Set(cacheKey(req, account), &CacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   c.Clock.Now(),
+	})
+}
+
+type refreshContextKey struct{}
+
+// WithCacheRefresh returns a copy of ctx that makes the requests created
+// with it skip the Cache of the Client, storing their fresh response
+// instead.
+func WithCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshContextKey{}, true)
+}
+
+func refreshForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(refreshContextKey{}).(bool)
+	return forced
+}
+
+// LRUStorage is a CacheStorage holding a bounded number of entries. Once
+// full, it evicts the least recently used entry.
+type LRUStorage struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewLRUStorage returns an LRUStorage holding up to size entries. A size
+// lower than 1 defaults to DefaultCacheSize.
+func NewLRUStorage(size int) *LRUStorage {
+	if size < 1 {
+		size = DefaultCacheSize
+	}
+	return &LRUStorage{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements the CacheStorage interface.
+func (s *LRUStorage) Get(key string) (*CacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+// Set implements the CacheStorage interface.
+func (s *LRUStorage) Set(key string, e *CacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*lruItem).entry = e
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&lruItem{key: key, entry: e})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Delete implements the CacheStorage interface.
+func (s *LRUStorage) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		s.order.Remove(el)
+		delete(s.entries, key)
+	}
+}
+
+// Len returns the number of entries stored.
+func (s *LRUStorage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
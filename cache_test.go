@@ -0,0 +1,226 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Cache(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		mu.Lock()
+		hits[name]++
+		mu.Unlock()
+
+		switch name {
+		case "fuelstatus":
+			w.Write([]byte(`[{"tanklevelpercent":{"value":"84","timestamp":1}}]`))
+		case "odo":
+			w.Write([]byte(`{"odo":{"value":"1000","timestamp":1}}`))
+		case "vehiclelockstatus":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()), WithCache(&Cache{
+		TTLs: map[Container]time.Duration{
+			ContainerFuelStatus:        time.Minute,
+			ContainerVehicleLockStatus: time.Minute,
+			ContainerVehicleStatus:     time.Minute,
+			ContainerPayAsYouDrive:     10 * time.Second,
+		},
+		Clock: clock,
+	}))
+	assert.NoError(t, err)
+	ctx := context.Background()
+	opts := &Options{VehicleID: fakeVehicleID}
+
+	_, resp, err := c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.NoError(t, err)
+	assert.False(t, resp.FromCache)
+	assert.Equal(t, 1, resp.Attempts)
+
+	clock.Advance(30 * time.Second)
+	snapshot, resp, err := c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.NoError(t, err)
+	assert.True(t, resp.FromCache)
+	assert.Equal(t, 30*time.Second, resp.Age)
+	assert.Equal(t, 0, resp.Attempts)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "84", *snapshot.Status.TankLevelPercent.Value)
+	assert.Equal(t, 1, hits["fuelstatus"])
+
+	_, resp, err = c.FuelStatus.GetFuelStatusSnapshot(WithCacheRefresh(ctx), opts)
+	assert.NoError(t, err)
+	assert.False(t, resp.FromCache)
+	assert.Equal(t, 2, hits["fuelstatus"])
+
+	clock.Advance(59 * time.Second)
+	_, resp, _ = c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.True(t, resp.FromCache)
+	clock.Advance(time.Second)
+	_, resp, _ = c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.False(t, resp.FromCache)
+	assert.Equal(t, 3, hits["fuelstatus"])
+
+	// No data available is not cached.
+	for i := 0; i < 2; i++ {
+		_, resp, err = c.VehicleLockStatus.GetVehicleLockStatusSnapshot(ctx, opts)
+		assert.ErrorIs(t, err, ErrNoDataAvailable)
+		assert.False(t, resp.FromCache)
+	}
+	assert.Equal(t, 2, hits["vehiclelockstatus"])
+
+	// Errors are not cached.
+	for i := 0; i < 2; i++ {
+		_, resp, err = c.VehicleStatus.GetVehicleStatusSnapshot(ctx, opts)
+		assert.Error(t, err)
+		assert.False(t, resp.FromCache)
+	}
+	assert.Equal(t, 2, hits["vehiclestatus"])
+
+	// Containers without TTL are not cached.
+	for i := 0; i < 2; i++ {
+		c.ElectricVehicleStatus.GetElectricVehicleStatusSnapshot(ctx, opts)
+	}
+	assert.Equal(t, 2, hits["electricvehicle"])
+
+	// Single resources are cached for the TTL of their container.
+	for i := 0; i < 2; i++ {
+		_, resp, err = c.Resources.GetResource(ctx, opts, "odo")
+		assert.NoError(t, err)
+	}
+	assert.True(t, resp.FromCache)
+	clock.Advance(10 * time.Second)
+	c.Resources.GetResource(ctx, opts, "odo")
+	assert.Equal(t, 2, hits["odo"])
+}
+
+func TestClient_Cache_credentials(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`[{"tanklevelpercent":{"value":"84","timestamp":1}}]`))
+	}))
+	defer server.Close()
+
+	creds := &Credentials{}
+	creds.AddAccount("a", &fakeTokenSource{tokens: []string{"token-a"}}, fakeVehicleID)
+	c, err := New(
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithCredentials(creds),
+		WithCache(&Cache{TTLs: map[Container]time.Duration{ContainerFuelStatus: time.Hour}}),
+	)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	opts := &Options{VehicleID: fakeVehicleID}
+
+	for i := 0; i < 2; i++ {
+		_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, hits)
+
+	creds.RemoveAccount("a")
+	_, resp, err := c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	var missingErr *MissingCredentialsError
+	assert.True(t, errors.As(err, &missingErr))
+	assert.False(t, resp.FromCache)
+
+	// Another account of the vehicle does not get the cached response.
+	creds.AddAccount("b", &fakeTokenSource{tokens: []string{"token-b"}}, fakeVehicleID)
+	_, resp, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.NoError(t, err)
+	assert.False(t, resp.FromCache)
+	assert.Equal(t, 2, hits)
+}
+
+func TestCache_ttl(t *testing.T) {
+	cache := &Cache{
+		TTLs: map[Container]time.Duration{
+			ContainerVehicleStatus:     time.Minute,
+			ContainerVehicleLockStatus: time.Second,
+		},
+		DefaultTTL: time.Hour,
+	}
+	base := "https://api.mercedes-benz.com/vehicledata/v2/vehicles/" + fakeVehicleID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{name: "container", method: http.MethodGet, path: "/containers/vehiclestatus", want: time.Minute},
+		{name: "container without TTL", method: http.MethodGet, path: "/containers/fuelstatus"},
+		{name: "shared resource", method: http.MethodGet, path: "/resources/doorlockstatusdecklid", want: time.Second},
+		{name: "resources", method: http.MethodGet, path: "/resources", want: time.Hour},
+		{name: "not a GET", method: http.MethodPost, path: "/containers/vehiclestatus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, base+tt.path, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cache.ttl(req))
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/resources", nil)
+	var nilCache *Cache
+	assert.Zero(t, nilCache.ttl(req))
+}
+
+func TestLRUStorage(t *testing.T) {
+	s := NewLRUStorage(2)
+
+	s.Set("a", &CacheEntry{StatusCode: 1})
+	s.Set("b", &CacheEntry{StatusCode: 2})
+	_, ok := s.Get("a")
+	assert.True(t, ok)
+
+	s.Set("c", &CacheEntry{StatusCode: 3})
+	assert.Equal(t, 2, s.Len())
+	_, ok = s.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	s.Set("a", &CacheEntry{StatusCode: 4})
+	e, ok := s.Get("a")
+	if assert.True(t, ok) {
+		assert.Equal(t, 4, e.StatusCode)
+	}
+
+	s.Delete("a")
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, s.Len())
+
+	assert.Equal(t, DefaultCacheSize, NewLRUStorage(0).size)
+}
+
+func TestWithCache(t *testing.T) {
+	_, err := New(WithCache(nil))
+	assert.Error(t, err)
+
+	_, err = New(WithCache(&Cache{TTLs: map[Container]time.Duration{ContainerFuelStatus: -time.Second}}))
+	assert.Error(t, err)
+
+	cache := &Cache{}
+	c, err := New(WithCache(cache))
+	assert.NoError(t, err)
+	assert.Same(t, cache, c.Cache)
+}
@@ -0,0 +1,267 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Powertrain is the kind of drive of a vehicle.
+type Powertrain int
+
+// Powertrains of vehicles.
+const (
+	// PowertrainUnknown is the powertrain of a vehicle offering neither
+	// fuel nor battery resources.
+	PowertrainUnknown Powertrain = iota
+	// PowertrainICE is a vehicle with an internal combustion engine.
+	PowertrainICE
+	// PowertrainBEV is a battery electric vehicle.
+	PowertrainBEV
+	// PowertrainPHEV is a plug-in hybrid electric vehicle.
+	PowertrainPHEV
+)
+
+func (p Powertrain) String() string {
+	switch p {
+	case PowertrainUnknown:
+		return "unknown"
+	case PowertrainICE:
+		return "ICE"
+	case PowertrainBEV:
+		return "BEV"
+	case PowertrainPHEV:
+		return "PHEV"
+	}
+	return "Powertrain(" + strconv.Itoa(int(p)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler using the powertrain name.
+func (p Powertrain) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// Capabilities describes the data a vehicle offers, as detected from its
+// available resources.
+type Capabilities struct {
+	VehicleID  string     `json:"vehicleId"`
+	Powertrain Powertrain `json:"powertrain"`
+	// Containers are the containers holding at least one resource of the
+	// vehicle, in the order of Containers().
+	Containers []Container `json:"containers"`
+	// Resources are the names of the available resources, sorted.
+	Resources  []string  `json:"resources"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// DetectCapabilities detects the capabilities of a vehicle from its
+// available resources, as returned by GetAvailableResources. A vehicle
+// with fuel and battery resources is a PHEV. The Mercedes API only lists the
+// resources of the granted scopes, so the containers whose scope has not
+// been granted are missing from the capabilities.
+func DetectCapabilities(vehicleID string, resources []*ResourceMetaInfo, now time.Time) *Capabilities {
+	c := &Capabilities{VehicleID: vehicleID, Containers: []Container{}, Resources: []string{}, DetectedAt: now}
+
+	available := make(map[string]bool)
+	for _, meta := range resources {
+		if name := metaName(meta); name != "" && !available[name] {
+			available[name] = true
+			c.Resources = append(c.Resources, name)
+		}
+	}
+	sort.Strings(c.Resources)
+
+	for _, ct := range Containers() {
+		for _, name := range containers[ct].resources {
+			if available[name] {
+				c.Containers = append(c.Containers, ct)
+				break
+			}
+		}
+	}
+
+	fuel := c.HasContainer(ContainerFuelStatus)
+	battery := c.HasContainer(ContainerElectricVehicleStatus)
+	switch {
+	case fuel && battery:
+		c.Powertrain = PowertrainPHEV
+	case battery:
+		c.Powertrain = PowertrainBEV
+	case fuel:
+		c.Powertrain = PowertrainICE
+	}
+	return c
+}
+
+// metaName returns the name of an available resource, falling back to the
+// last element of its href.
+func metaName(meta *ResourceMetaInfo) string {
+	switch {
+	case meta == nil:
+		return ""
+	case meta.Name != nil:
+		return *meta.Name
+	case meta.Href != nil:
+		return path.Base(*meta.Href)
+	}
+	return ""
+}
+
+// HasContainer reports whether the vehicle offers the container.
+func (c *Capabilities) HasContainer(ct Container) bool {
+	for _, available := range c.Containers {
+		if available == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// HasResource reports whether the vehicle offers the resource.
+func (c *Capabilities) HasResource(name string) bool {
+	i := sort.SearchStrings(c.Resources, name)
+	return i < len(c.Resources) && c.Resources[i] == name
+}
+
+// Services returns the names of the services reading the containers
+// offered by the vehicle.
+func (c *Capabilities) Services() []string {
+	services := make([]string, len(c.Containers))
+	for i, ct := range c.Containers {
+		services[i] = ct.Service()
+	}
+	return services
+}
+
+// ErrUnsupported is matched, using errors.Is, by the *UnsupportedError
+// returned for the containers and resources a vehicle does not offer.
+var ErrUnsupported = errors.New("not offered by the vehicle")
+
+// UnsupportedError is returned, before sending the request, when the
+// detected capabilities of a vehicle show that it does not offer the
+// container or resource requested. See WithCapabilityDetection.
+type UnsupportedError struct {
+	VehicleID string
+	Container Container
+	Resource  string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Resource != "" {
+		return fmt.Sprintf("vehicle %q does not offer the resource %q", e.VehicleID, e.Resource)
+	}
+	return fmt.Sprintf("vehicle %q does not offer the container %q", e.VehicleID, e.Container)
+}
+
+// Is reports whether target is ErrUnsupported.
+func (e *UnsupportedError) Is(target error) bool { return target == ErrUnsupported }
+
+// CapabilitiesService detects the capabilities of vehicles.
+type CapabilitiesService service
+
+// GetCapabilities gets the available resources of a vehicle and detects
+// its capabilities. With WithCapabilityDetection, the capabilities are
+// cached per vehicle, and a nil Response is returned when they are read
+// from the cache. The Capabilities returned must not be modified.
+func (s *CapabilitiesService) GetCapabilities(ctx context.Context, opts *Options) (*Capabilities, *Response, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+	cache := s.client.capabilities
+	if caps := cache.get(opts.VehicleID); caps != nil {
+		return caps, nil, nil
+	}
+
+	resources, resp, err := s.client.Resources.GetAvailableResources(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	caps := DetectCapabilities(opts.VehicleID, resources, cache.now())
+	cache.put(caps)
+	return caps, resp, nil
+}
+
+// capabilityCache caches the capabilities of vehicles for a TTL. A nil
+// cache caches nothing.
+type capabilityCache struct {
+	ttl   time.Duration
+	clock Clock
+
+	mu      sync.Mutex
+	entries map[string]*Capabilities
+}
+
+func newCapabilityCache(ttl time.Duration) *capabilityCache {
+	return &capabilityCache{ttl: ttl, clock: systemClock{}, entries: make(map[string]*Capabilities)}
+}
+
+func (c *capabilityCache) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// get returns the cached capabilities of a vehicle, or nil if they are
+// unknown or expired.
+func (c *capabilityCache) get(vehicleID string) *Capabilities {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	caps, ok := c.entries[vehicleID]
+	if !ok {
+		return nil
+	}
+	if !c.clock.Now().Before(caps.DetectedAt.Add(c.ttl)) {
+		delete(c.entries, vehicleID)
+		return nil
+	}
+	return caps
+}
+
+func (c *capabilityCache) put(caps *Capabilities) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[caps.VehicleID] = caps
+}
+
+// checkCapabilities checks that the vehicle of a request path offers the
+// container or resource requested. It is a no-op unless the capabilities
+// of the vehicle are cached. As the available resources only list those of
+// the granted scopes, only the containers whose scope is known to be
+// granted are checked: the others may be offered once the owner consents.
+func (c *Client) checkCapabilities(path string) error {
+	t := parseTarget(path)
+	caps := c.capabilities.get(t.vehicleID)
+	if caps == nil {
+		return nil
+	}
+
+	switch {
+	case t.container != "":
+		if c.scopeGranted(t.container.Scope()) && !caps.HasContainer(t.container) {
+			return &UnsupportedError{VehicleID: t.vehicleID, Container: t.container}
+		}
+	case t.resource != "":
+		if caps.HasResource(t.resource) {
+			return nil
+		}
+		for _, ct := range resourceContainers(t.resource) {
+			if c.scopeGranted(ct.Scope()) {
+				return &UnsupportedError{VehicleID: t.vehicleID, Resource: t.resource}
+			}
+		}
+	}
+	return nil
+}
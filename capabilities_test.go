@@ -0,0 +1,181 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func metas(names ...string) []*ResourceMetaInfo {
+	resources := make([]*ResourceMetaInfo, len(names))
+	for i, name := range names {
+		resources[i] = &ResourceMetaInfo{Name: String(name)}
+	}
+	return resources
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		name           string
+		resources      []*ResourceMetaInfo
+		wantPowertrain Powertrain
+		wantContainers []Container
+	}{
+		{
+			name:           "ICE",
+			resources:      metas("tanklevelpercent", "rangeliquid", "odo", "doorlockstatusvehicle"),
+			wantPowertrain: PowertrainICE,
+			wantContainers: []Container{ContainerVehicleLockStatus, ContainerFuelStatus, ContainerPayAsYouDrive},
+		},
+		{
+			name:           "BEV",
+			resources:      metas("soc", "rangeelectric", "doorstatusfrontleft"),
+			wantPowertrain: PowertrainBEV,
+			wantContainers: []Container{ContainerVehicleStatus, ContainerElectricVehicleStatus},
+		},
+		{
+			name:           "PHEV",
+			resources:      metas("soc", "tanklevelpercent"),
+			wantPowertrain: PowertrainPHEV,
+			wantContainers: []Container{ContainerFuelStatus, ContainerElectricVehicleStatus},
+		},
+		{
+			name:           "shared resource",
+			resources:      metas("doorlockstatusdecklid"),
+			wantPowertrain: PowertrainUnknown,
+			wantContainers: []Container{ContainerVehicleStatus, ContainerVehicleLockStatus},
+		},
+		{
+			name:           "href only",
+			resources:      []*ResourceMetaInfo{{Href: String("/vehicles/" + fakeVehicleID + "/resources/rangeliquid")}, nil},
+			wantPowertrain: PowertrainICE,
+			wantContainers: []Container{ContainerFuelStatus},
+		},
+		{
+			name:           "none",
+			wantPowertrain: PowertrainUnknown,
+			wantContainers: []Container{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := DetectCapabilities(fakeVehicleID, tt.resources, now)
+
+			assert.Equal(t, fakeVehicleID, caps.VehicleID)
+			assert.Equal(t, tt.wantPowertrain, caps.Powertrain)
+			assert.Equal(t, tt.wantContainers, caps.Containers)
+			assert.Equal(t, now, caps.DetectedAt)
+		})
+	}
+
+	caps := DetectCapabilities(fakeVehicleID, metas("tanklevelpercent", "odo", "odo"), now)
+	assert.Equal(t, []string{"odo", "tanklevelpercent"}, caps.Resources)
+	assert.True(t, caps.HasResource("odo"))
+	assert.False(t, caps.HasResource("soc"))
+	assert.Equal(t, []string{"FuelStatusService", "PayAsYouDriveService"}, caps.Services())
+	assert.Equal(t, "ICE", caps.Powertrain.String())
+}
+
+func TestCapabilitiesService_GetCapabilities(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/resources"):
+			w.Write([]byte(`[{"name":"tanklevelpercent"},{"name":"odo"}]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithGrantedScopes(ScopesFor(Containers()...)...),
+		WithCapabilityDetection(time.Hour),
+	)
+	assert.NoError(t, err)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	c.capabilities.clock = clock
+	ctx := context.Background()
+	opts := &Options{VehicleID: fakeVehicleID}
+
+	caps, resp, err := c.Capabilities.GetCapabilities(ctx, opts)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, PowertrainICE, caps.Powertrain)
+
+	cached, resp, err := c.Capabilities.GetCapabilities(ctx, opts)
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.Same(t, caps, cached)
+
+	_, _, err = c.ElectricVehicleStatus.GetElectricVehicleStatusSnapshot(ctx, opts)
+	var unsupportedErr *UnsupportedError
+	if assert.True(t, errors.As(err, &unsupportedErr)) {
+		assert.Equal(t, ContainerElectricVehicleStatus, unsupportedErr.Container)
+	}
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.NotErrorIs(t, err, ErrVehicleNotFound)
+
+	_, _, err = c.Resources.GetResource(ctx, opts, "soc")
+	assert.True(t, errors.As(err, &unsupportedErr))
+	assert.Equal(t, []string{"resources"}, paths)
+
+	report, err := c.GetVehicleReport(ctx, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ReadNotAvailable, report.Results[ContainerElectricVehicleStatus].Status)
+	assert.Equal(t, ReadNoData, report.Results[ContainerFuelStatus].Status)
+	assert.ElementsMatch(t, []string{"resources", "fuelstatus", "payasyoudrive"}, paths)
+
+	clock.Advance(time.Hour)
+	_, resp, err = c.Capabilities.GetCapabilities(ctx, opts)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, "resources", paths[len(paths)-1])
+
+	_, _, err = c.Capabilities.GetCapabilities(ctx, &Options{})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+
+	// The resources of the scopes not granted are not listed, so their
+	// containers are requested anyway.
+	for _, scopes := range [][]string{{ScopeFuelStatus}, nil} {
+		options := []ClientOption{WithBaseURL(server.URL + "/"), WithHTTPClient(server.Client()), WithCapabilityDetection(time.Hour)}
+		if scopes != nil {
+			options = append(options, WithGrantedScopes(scopes...))
+		}
+		c, err = New(options...)
+		assert.NoError(t, err)
+		_, _, err = c.Capabilities.GetCapabilities(ctx, opts)
+		assert.NoError(t, err)
+
+		_, _, err = c.ElectricVehicleStatus.GetElectricVehicleStatusSnapshot(ctx, opts)
+		assert.NotErrorIs(t, err, ErrUnsupported)
+		_, _, err = c.Resources.GetResource(ctx, opts, "soc")
+		assert.NotErrorIs(t, err, ErrUnsupported)
+	}
+}
+
+func TestWithCapabilityDetection(t *testing.T) {
+	_, err := New(WithCapabilityDetection(0))
+	assert.Error(t, err)
+
+	c, err := New()
+	assert.NoError(t, err)
+	assert.Nil(t, c.capabilities)
+}
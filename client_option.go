@@ -0,0 +1,251 @@
+package merche
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Environment identifies a Mercedes API environment.
+type Environment int
+
+const (
+	// Production is the Mercedes API serving real vehicles.
+	Production Environment = iota
+	// Sandbox is the Mercedes API serving the EXVETESTVIN test vehicles.
+	Sandbox
+)
+
+func (e Environment) String() string {
+	switch e {
+	case Production:
+		return "production"
+	case Sandbox:
+		return "sandbox"
+	}
+	return fmt.Sprintf("Environment(%d)", int(e))
+}
+
+func (e Environment) pathPrefix() (string, error) {
+	switch e {
+	case Production:
+		return apiPathPrefix, nil
+	case Sandbox:
+		return sandboxAPIPathPrefix, nil
+	}
+	return "", fmt.Errorf("unknown environment %v", e)
+}
+
+// ClientOption configures a Client created with New.
+type ClientOption func(*clientOptions) error
+
+type clientOptions struct {
+	baseURL     *url.URL
+	userAgent   *string
+	httpClient  *http.Client
+	timeout     time.Duration
+	env         Environment
+	retryPolicy *RetryPolicy
+	rateLimiter Limiter
+	scopes      []string
+	credentials CredentialResolver
+	capsTTL     time.Duration
+	cache       *Cache
+}
+
+// New returns a new Mercedes API client configured with opts. Unlike
+// NewClient, the configuration is validated before the client is returned.
+func New(opts ...ClientOption) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix, err := o.env.pathPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := o.httpClient
+	if o.timeout > 0 {
+		hc := http.Client{}
+		if httpClient != nil {
+			hc = *httpClient
+		}
+		hc.Timeout = o.timeout
+		httpClient = &hc
+	}
+
+	c := NewClient(httpClient)
+	c.pathPrefix = prefix
+	if o.baseURL != nil {
+		c.BaseURL = o.baseURL
+	}
+	if o.userAgent != nil {
+		c.UserAgent = *o.userAgent
+	}
+	c.RetryPolicy = o.retryPolicy
+	c.RateLimiter = o.rateLimiter
+	c.Credentials = o.credentials
+	c.Cache = o.cache
+	if o.capsTTL > 0 {
+		c.capabilities = newCapabilityCache(o.capsTTL)
+	}
+	if o.scopes != nil {
+		c.grantedScopes = make(map[string]bool)
+		for _, scope := range o.scopes {
+			c.grantedScopes[scope] = true
+		}
+	}
+
+	return c, nil
+}
+
+// WithBaseURL sets the base URL for API requests. It must be an absolute URL
+// with a trailing slash.
+func WithBaseURL(rawURL string) ClientOption {
+	return func(o *clientOptions) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() || u.Host == "" {
+			return fmt.Errorf("BaseURL must be an absolute URL, but %q is not", rawURL)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			return fmt.Errorf("BaseURL must have a trailing slash, but %q does not", rawURL)
+		}
+		o.baseURL = u
+		return nil
+	}
+}
+
+// WithUserAgent sets the user agent used when communicating with the
+// Mercedes API.
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) error {
+		o.userAgent = &ua
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests. Provide an
+// http.Client that performs the authentication to use the API methods.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) error {
+		if httpClient == nil {
+			return errors.New("http client must not be nil")
+		}
+		o.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTimeout sets the time limit of every attempt of a request. The
+// http.Client given with WithHTTPClient is copied, not modified.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got %v", d)
+		}
+		o.timeout = d
+		return nil
+	}
+}
+
+// WithEnvironment selects the Mercedes API environment. Defaults to
+// Production.
+func WithEnvironment(env Environment) ClientOption {
+	return func(o *clientOptions) error {
+		if _, err := env.pathPrefix(); err != nil {
+			return err
+		}
+		o.env = env
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(p *RetryPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		o.retryPolicy = p
+		return nil
+	}
+}
+
+// WithRateLimiter sets the Limiter waited on before sending each request.
+func WithRateLimiter(l Limiter) ClientOption {
+	return func(o *clientOptions) error {
+		o.rateLimiter = l
+		return nil
+	}
+}
+
+// WithGrantedScopes sets the scopes granted to the token of the client. When
+// set, requests that require a scope that has not been granted fail with a
+// *MissingScopeError before they are sent, instead of being rejected by the
+// Mercedes API. An empty list leaves the granted scopes unknown, as token
+// responses may omit them when they are the ones requested.
+func WithGrantedScopes(scopes ...string) ClientOption {
+	return func(o *clientOptions) error {
+		if len(scopes) == 0 {
+			o.scopes = nil
+			return nil
+		}
+		o.scopes = append([]string{}, scopes...)
+		return nil
+	}
+}
+
+// WithCredentials sets the CredentialResolver of the account authorizing
+// each vehicle, so that a single Client reads vehicles of several Mercedes me
+// accounts. Requests for a vehicle without credentials fail with a
+// *MissingCredentialsError before they are sent. The http.Client given with
+// WithHTTPClient must not authorize the requests itself.
+func WithCredentials(r CredentialResolver) ClientOption {
+	return func(o *clientOptions) error {
+		if r == nil {
+			return errors.New("credential resolver must not be nil")
+		}
+		o.credentials = r
+		return nil
+	}
+}
+
+// WithCapabilityDetection caches the capabilities of each vehicle, detected
+// by CapabilitiesService, for ttl. Requests for a container or resource that
+// a vehicle with cached capabilities does not offer fail with an
+// *UnsupportedError before they are sent, and GetVehicleReport skips them.
+// Only the containers whose scope has been granted, as set by
+// WithGrantedScopes, are checked, since the vehicle may offer the others
+// once its owner consents.
+func WithCapabilityDetection(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if ttl <= 0 {
+			return fmt.Errorf("capability TTL must be positive, got %v", ttl)
+		}
+		o.capsTTL = ttl
+		return nil
+	}
+}
+
+// WithCache sets the Cache of the responses of GET requests.
+func WithCache(cache *Cache) ClientOption {
+	return func(o *clientOptions) error {
+		if cache == nil {
+			return errors.New("cache must not be nil")
+		}
+		for ct, ttl := range cache.TTLs {
+			if ttl < 0 {
+				return fmt.Errorf("TTL of %v must not be negative, got %v", ct, ttl)
+			}
+		}
+		o.cache = cache
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package merche
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	httpClient := &http.Client{}
+
+	tests := []struct {
+		name           string
+		opts           []ClientOption
+		wantBaseURL    string
+		wantUserAgent  string
+		wantPathPrefix string
+		wantErr        bool
+	}{
+		{
+			name:           "defaults",
+			wantBaseURL:    defaultBaseURL,
+			wantUserAgent:  userAgent,
+			wantPathPrefix: apiPathPrefix,
+		},
+		{
+			name: "sandbox environment",
+			opts: []ClientOption{
+				WithEnvironment(Sandbox),
+				WithUserAgent("my-app"),
+			},
+			wantBaseURL:    defaultBaseURL,
+			wantUserAgent:  "my-app",
+			wantPathPrefix: sandboxAPIPathPrefix,
+		},
+		{
+			name: "custom base url",
+			opts: []ClientOption{
+				WithBaseURL("http://localhost:8080/api/"),
+				WithHTTPClient(httpClient),
+				WithTimeout(time.Second),
+			},
+			wantBaseURL:    "http://localhost:8080/api/",
+			wantUserAgent:  userAgent,
+			wantPathPrefix: apiPathPrefix,
+		},
+		{
+			name:    "base url without trailing slash",
+			opts:    []ClientOption{WithBaseURL("http://localhost:8080/api")},
+			wantErr: true,
+		},
+		{
+			name:    "relative base url",
+			opts:    []ClientOption{WithBaseURL("/api/")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown environment",
+			opts:    []ClientOption{WithEnvironment(Environment(42))},
+			wantErr: true,
+		},
+		{
+			name:    "nil http client",
+			opts:    []ClientOption{WithHTTPClient(nil)},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			opts:    []ClientOption{WithTimeout(-time.Second)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.wantBaseURL, c.BaseURL.String())
+			assert.Equal(t, tt.wantUserAgent, c.UserAgent)
+			assert.Equal(t, tt.wantPathPrefix, c.pathPrefix)
+		})
+	}
+
+	assert.Zero(t, httpClient.Timeout, "WithTimeout must not modify the given http client")
+}
+
+func TestNew_SandboxRequest(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	c, err := New(
+		WithEnvironment(Sandbox),
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+	)
+	assert.NoError(t, err)
+
+	_, _, err = c.FuelStatus.GetFuelStatus(context.Background(), &Options{VehicleID: fakeVehicleID})
+	assert.NoError(t, err)
+	assert.Equal(t, "/vehicledata_tryout/v2/vehicles/EXVETESTVIN000001/containers/fuelstatus", path)
+}
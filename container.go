@@ -0,0 +1,166 @@
+package merche
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Container identifies a Mercedes API container. A container groups all
+// the resources of an API product so they can be read out in one request.
+type Container string
+
+// Mercedes API containers.
+const (
+	ContainerVehicleStatus         Container = "vehiclestatus"
+	ContainerVehicleLockStatus     Container = "vehiclelockstatus"
+	ContainerFuelStatus            Container = "fuelstatus"
+	ContainerElectricVehicleStatus Container = "electricvehicle"
+	ContainerPayAsYouDrive         Container = "payasyoudrive"
+)
+
+type containerInfo struct {
+	scope     string
+	service   string
+	resources []string
+}
+
+var containers = map[Container]containerInfo{
+	ContainerVehicleStatus: {
+		scope:     ScopeVehicleStatus,
+		service:   "VehicleStatusService",
+		resources: resourceNames(VehicleStatus{}),
+	},
+	ContainerVehicleLockStatus: {
+		scope:     ScopeVehicleLockStatus,
+		service:   "VehicleLockStatusService",
+		resources: resourceNames(VehicleLockStatus{}),
+	},
+	ContainerFuelStatus: {
+		scope:     ScopeFuelStatus,
+		service:   "FuelStatusService",
+		resources: resourceNames(FuelStatus{}),
+	},
+	ContainerElectricVehicleStatus: {
+		scope:     ScopeElectricVehicleStatus,
+		service:   "ElectricVehicleStatusService",
+		resources: resourceNames(ElectricVehicleStatus{}),
+	},
+	ContainerPayAsYouDrive: {
+		scope:     ScopePayAsYouDrive,
+		service:   "PayAsYouDriveService",
+		resources: resourceNames(PayAsYouDriveStatus{}),
+	},
+}
+
+// Containers returns all the containers of the Mercedes API.
+func Containers() []Container {
+	return []Container{
+		ContainerVehicleStatus,
+		ContainerVehicleLockStatus,
+		ContainerFuelStatus,
+		ContainerElectricVehicleStatus,
+		ContainerPayAsYouDrive,
+	}
+}
+
+// Scope returns the OAuth2 scope required to read the container.
+func (c Container) Scope() string { return containers[c].scope }
+
+// Service returns the name of the service reading the container.
+func (c Container) Service() string { return containers[c].service }
+
+// Resources returns the names of the resources of the container.
+func (c Container) Resources() []string {
+	return append([]string(nil), containers[c].resources...)
+}
+
+// getContainer gets the resources of any container, by resource name.
+func (c *Client) getContainer(ctx context.Context, opts *Options, ct Container) (map[string]*Resource, *Response, error) {
+	path, err := c.vehiclePath(opts, "containers", string(ct))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw []map[string]*Resource
+	resp, err := c.Do(req, &raw)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	resources := make(map[string]*Resource)
+	for _, item := range raw {
+		for name, r := range item {
+			if r == nil {
+				continue
+			}
+			if _, ok := resources[name]; ok {
+				return nil, resp, &DuplicateResourceError{Resource: name}
+			}
+			r.Name = name
+			resources[name] = r
+		}
+	}
+	return resources, resp, nil
+}
+
+// resourceContainers returns the containers holding a resource.
+func resourceContainers(resource string) []Container {
+	var cs []Container
+	for _, c := range Containers() {
+		for _, r := range containers[c].resources {
+			if r == resource {
+				cs = append(cs, c)
+			}
+		}
+	}
+	return cs
+}
+
+// resourceNames returns the names of the resources of a status struct.
+func resourceNames(status interface{}) []string {
+	t := reflect.TypeOf(status)
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = resourceName(t.Field(i))
+	}
+	return names
+}
+
+// target describes the vehicle data addressed by an API request path.
+type target struct {
+	vehicleID string
+	container Container
+	resource  string
+}
+
+// parseTarget extracts the vehicle, container and resource addressed by
+// the path of an API request like
+// vehicledata/v2/vehicles/{vehicleId}/containers/{containerId}.
+func parseTarget(path string) target {
+	var t target
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] != "vehicles" {
+			continue
+		}
+		t.vehicleID = segments[i+1]
+		if i+3 < len(segments) {
+			switch segments[i+2] {
+			case "containers":
+				t.container = Container(segments[i+3])
+			case "resources":
+				t.resource = segments[i+3]
+			}
+		}
+		break
+	}
+	return t
+}
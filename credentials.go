@@ -0,0 +1,170 @@
+package merche
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/jferrl/go-merche/auth"
+)
+
+// Account is a Mercedes me account authorizing vehicles.
+type Account struct {
+	ID     string
+	Source auth.TokenSource
+}
+
+// CredentialResolver resolves the account authorizing a vehicle, so that a
+// single Client can read vehicles of several accounts.
+type CredentialResolver interface {
+	// ResolveCredentials returns the account authorizing the vehicle, or a
+	// *MissingCredentialsError if there is none.
+	ResolveCredentials(ctx context.Context, vehicleID string) (*Account, error)
+}
+
+// MissingCredentialsError is returned, before sending the request, when no
+// account authorizes the vehicle of a request. See WithCredentials.
+type MissingCredentialsError struct {
+	VehicleID string
+}
+
+func (e *MissingCredentialsError) Error() string {
+	return fmt.Sprintf("no credentials for vehicle %q", e.VehicleID)
+}
+
+// Is reports whether target is ErrUnauthorized.
+func (e *MissingCredentialsError) Is(target error) bool { return target == ErrUnauthorized }
+
+// CredentialsError is returned when the token of the account authorizing a
+// vehicle cannot be obtained, like when it cannot be refreshed.
+type CredentialsError struct {
+	Account   string
+	VehicleID string
+	Err       error
+}
+
+func (e *CredentialsError) Error() string {
+	return fmt.Sprintf("credentials of account %q for vehicle %q: %v", e.Account, e.VehicleID, e.Err)
+}
+
+func (e *CredentialsError) Unwrap() error { return e.Err }
+
+// Credentials is a CredentialResolver holding the accounts authorizing
+// each vehicle. The zero value holds no account. It is safe for concurrent
+// use.
+type Credentials struct {
+	// OnRefresh, if set, is called with every new token of an account
+	// after its first one, like after a refresh.
+	OnRefresh func(account string, tok *auth.Token)
+	// OnError, if set, is called when the token of an account cannot be
+	// obtained. An *auth.RefreshError means that the account must go
+	// through the authorization flow again, while other errors, like
+	// network failures, are transient.
+	OnError func(account string, err error)
+
+	mu       sync.RWMutex
+	accounts map[string]*Account
+	vehicles map[string]string
+}
+
+// AddAccount adds the account id, whose tokens are returned by ts, as the
+// one authorizing the given vehicles. Adding an account again replaces its
+// token source and adds the vehicles to it.
+func (c *Credentials) AddAccount(id string, ts auth.TokenSource, vehicleIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accounts == nil {
+		c.accounts = make(map[string]*Account)
+		c.vehicles = make(map[string]string)
+	}
+	c.accounts[id] = &Account{ID: id, Source: &hookedTokenSource{account: id, source: ts, credentials: c}}
+	for _, vin := range vehicleIDs {
+		c.vehicles[vin] = id
+	}
+}
+
+// RemoveAccount removes the account id and its vehicles.
+func (c *Credentials) RemoveAccount(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.accounts, id)
+	for vin, account := range c.vehicles {
+		if account == id {
+			delete(c.vehicles, vin)
+		}
+	}
+}
+
+// ResolveCredentials implements the CredentialResolver interface.
+func (c *Credentials) ResolveCredentials(_ context.Context, vehicleID string) (*Account, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	account, ok := c.accounts[c.vehicles[vehicleID]]
+	if !ok {
+		return nil, &MissingCredentialsError{VehicleID: vehicleID}
+	}
+	return account, nil
+}
+
+// hookedTokenSource calls the hooks of Credentials for the tokens of an
+// account.
+type hookedTokenSource struct {
+	account     string
+	source      auth.TokenSource
+	credentials *Credentials
+
+	mu   sync.Mutex
+	last string
+}
+
+func (s *hookedTokenSource) Token(ctx context.Context) (*auth.Token, error) {
+	tok, err := s.source.Token(ctx)
+	if err != nil {
+		if s.credentials.OnError != nil {
+			s.credentials.OnError(s.account, err)
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	refreshed := s.last != "" && s.last != tok.AccessToken
+	s.last = tok.AccessToken
+	s.mu.Unlock()
+
+	if refreshed && s.credentials.OnRefresh != nil {
+		s.credentials.OnRefresh(s.account, tok)
+	}
+	return tok, nil
+}
+
+// resolveAccount returns the account authorizing the vehicle of req, or
+// nil if the Client has no CredentialResolver or req does not address a
+// vehicle.
+func (c *Client) resolveAccount(req *http.Request) (*Account, error) {
+	vehicleID := parseTarget(req.URL.Path).vehicleID
+	if c.Credentials == nil || vehicleID == "" {
+		return nil, nil
+	}
+	return c.Credentials.ResolveCredentials(req.Context(), vehicleID)
+}
+
+// authorize sets the bearer token of account on req. A nil account leaves
+// req as it is.
+func (c *Client) authorize(req *http.Request, account *Account) (*http.Request, error) {
+	if account == nil {
+		return req, nil
+	}
+	tok, err := account.Source.Token(req.Context())
+	if err != nil {
+		return nil, &CredentialsError{Account: account.ID, VehicleID: parseTarget(req.URL.Path).vehicleID, Err: err}
+	}
+
+	// The clone shares the body of req, which is rewound between attempts.
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	return r, nil
+}
@@ -0,0 +1,105 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jferrl/go-merche/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTokenSource struct {
+	mu     sync.Mutex
+	tokens []string
+	err    error
+}
+
+func (s *fakeTokenSource) Token(context.Context) (*auth.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	tok := &auth.Token{AccessToken: s.tokens[0]}
+	if len(s.tokens) > 1 {
+		s.tokens = s.tokens[1:]
+	}
+	return tok, nil
+}
+
+func TestClient_Credentials(t *testing.T) {
+	tokens := map[string]string{
+		fakeVehicleID:  "token-a2",
+		otherVehicleID: "token-b",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for vin, tok := range tokens {
+			if strings.Contains(r.URL.Path, vin) && r.Header.Get("Authorization") == "Bearer "+tok {
+				w.Write([]byte(`[{"tanklevelpercent":{"value":"84","timestamp":1}}]`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errorCode":"invalid_token"}`))
+	}))
+	defer server.Close()
+
+	var refreshed []string
+	var failed []string
+	creds := &Credentials{
+		OnRefresh: func(account string, tok *auth.Token) { refreshed = append(refreshed, account+":"+tok.AccessToken) },
+		OnError:   func(account string, err error) { failed = append(failed, account) },
+	}
+	creds.AddAccount("a", &fakeTokenSource{tokens: []string{"token-a1", "token-a2"}}, fakeVehicleID)
+	creds.AddAccount("b", &fakeTokenSource{tokens: []string{"token-b"}}, otherVehicleID)
+	creds.AddAccount("c", &fakeTokenSource{err: &auth.RefreshError{Err: errors.New("invalid_grant")}}, "EXVETESTVIN000003")
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()), WithCredentials(creds))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: fakeVehicleID})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: fakeVehicleID})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a:token-a2"}, refreshed)
+
+	_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: otherVehicleID})
+	assert.NoError(t, err)
+
+	_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: "EXVETESTVIN000003"})
+	var credsErr *CredentialsError
+	var refreshErr *auth.RefreshError
+	if assert.True(t, errors.As(err, &credsErr)) {
+		assert.Equal(t, "c", credsErr.Account)
+		assert.True(t, errors.As(err, &refreshErr))
+	}
+	assert.Equal(t, []string{"c"}, failed)
+
+	_, resp, err := c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: "EXVETESTVIN000004"})
+	var missingErr *MissingCredentialsError
+	if assert.True(t, errors.As(err, &missingErr)) {
+		assert.Equal(t, "EXVETESTVIN000004", missingErr.VehicleID)
+	}
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, 0, resp.Attempts)
+
+	creds.RemoveAccount("b")
+	_, _, err = c.FuelStatus.GetFuelStatusSnapshot(ctx, &Options{VehicleID: otherVehicleID})
+	assert.True(t, errors.As(err, &missingErr))
+}
+
+func TestWithCredentials(t *testing.T) {
+	_, err := New(WithCredentials(nil))
+	assert.Error(t, err)
+
+	c, err := New(WithCredentials(&Credentials{}))
+	assert.NoError(t, err)
+	assert.NotNil(t, c.Credentials)
+}
@@ -0,0 +1,221 @@
+package merche
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the meaning of a ResourceChange.
+type ChangeKind string
+
+// Kinds of resource changes.
+const (
+	// ChangeAdded means the resource was not present in the old reading.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved means the resource is not present in the new reading.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeRefreshed means only the timestamp of the resource changed.
+	ChangeRefreshed ChangeKind = "refreshed"
+	// ChangeValue means the value changed in a way with no specific kind.
+	ChangeValue ChangeKind = "value_changed"
+
+	ChangeDoorOpened       ChangeKind = "door_opened"
+	ChangeDoorClosed       ChangeKind = "door_closed"
+	ChangeWindowOpened     ChangeKind = "window_opened"
+	ChangeWindowClosed     ChangeKind = "window_closed"
+	ChangeSunroofOpened    ChangeKind = "sunroof_opened"
+	ChangeSunroofClosed    ChangeKind = "sunroof_closed"
+	ChangeLocked           ChangeKind = "locked"
+	ChangeUnlocked         ChangeKind = "unlocked"
+	ChangeFuelIncreased    ChangeKind = "fuel_increased"
+	ChangeFuelDecreased    ChangeKind = "fuel_decreased"
+	ChangeChargeIncreased  ChangeKind = "charge_increased"
+	ChangeChargeDecreased  ChangeKind = "charge_decreased"
+	ChangeOdometerAdvanced ChangeKind = "odometer_advanced"
+)
+
+// ResourceChange is a change of a resource between two readings. Old is
+// nil if the resource was not present in the old reading, and New is nil
+// if it is not present anymore.
+type ResourceChange struct {
+	Resource string     `json:"resource"`
+	Kind     ChangeKind `json:"kind"`
+	Old      *Resource  `json:"old,omitempty"`
+	New      *Resource  `json:"new,omitempty"`
+}
+
+// Diff returns the changes of the resources of a status, like a
+// VehicleStatus or a FuelStatus, from the old reading to the new one. The
+// changes are ordered as the fields of the status, which must be a struct.
+// A nil status is a reading without resources.
+//
+// Resources with the same value and timestamp in both readings are
+// unchanged. Resources with the same value but a different timestamp are
+// reported as ChangeRefreshed.
+func Diff[T any](old, new *T) []ResourceChange {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	resourceType := reflect.TypeOf((*Resource)(nil))
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == resourceType {
+			names = append(names, resourceName(t.Field(i)))
+		}
+	}
+	return diff(names, statusResources(old), statusResources(new))
+}
+
+// statusResources returns the resources of a status by name.
+func statusResources[T any](status *T) map[string]*Resource {
+	resources := make(map[string]*Resource)
+	if status == nil {
+		return resources
+	}
+
+	v := reflect.ValueOf(status).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if r, ok := v.Field(i).Interface().(*Resource); ok && r != nil {
+			resources[resourceName(v.Type().Field(i))] = r
+		}
+	}
+	return resources
+}
+
+// diffResources returns the changes from old to new, ordered by resource
+// name.
+func diffResources(old, new map[string]*Resource) []ResourceChange {
+	names := make([]string, 0, len(old)+len(new))
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return diff(names, old, new)
+}
+
+func diff(names []string, old, new map[string]*Resource) []ResourceChange {
+	var changes []ResourceChange
+	for _, name := range names {
+		o, n := old[name], new[name]
+		if sameResource(o, n) {
+			continue
+		}
+		changes = append(changes, ResourceChange{
+			Resource: name,
+			Kind:     changeKind(name, o, n),
+			Old:      o,
+			New:      n,
+		})
+	}
+	return changes
+}
+
+// changeKind classifies the change of a resource from old to new.
+func changeKind(name string, old, new *Resource) ChangeKind {
+	switch {
+	case old == nil:
+		return ChangeAdded
+	case new == nil:
+		return ChangeRemoved
+	case equalPtr(old.Value, new.Value):
+		return ChangeRefreshed
+	}
+
+	switch {
+	case strings.HasPrefix(name, "doorstatus"):
+		return toggle(transition(old, new, func(open bool) bool { return open }), ChangeDoorOpened, ChangeDoorClosed)
+	case strings.HasPrefix(name, "windowstatus"):
+		return toggle(transition(old, new, WindowStatus.IsOpen), ChangeWindowOpened, ChangeWindowClosed)
+	}
+
+	switch name {
+	case "sunroofstatus":
+		return toggle(transition(old, new, SunroofStatus.IsOpen), ChangeSunroofOpened, ChangeSunroofClosed)
+	case "rooftopstatus":
+		return toggle(transition(old, new, RooftopStatus.IsLocked), ChangeLocked, ChangeUnlocked)
+	case "doorlockstatusvehicle":
+		return toggle(transition(old, new, DoorLockStatus.IsLocked), ChangeLocked, ChangeUnlocked)
+	case "doorlockstatusdecklid", "doorlockstatusgas":
+		return toggle(transition(old, new, func(unlocked bool) bool { return !unlocked }), ChangeLocked, ChangeUnlocked)
+	case "tanklevelpercent", "rangeliquid":
+		return trend(old, new, ChangeFuelIncreased, ChangeFuelDecreased)
+	case "soc", "rangeelectric":
+		return trend(old, new, ChangeChargeIncreased, ChangeChargeDecreased)
+	case "odo":
+		return trend(old, new, ChangeOdometerAdvanced, ChangeValue)
+	}
+	return ChangeValue
+}
+
+// state is the state of a resource before and after a change.
+type state struct {
+	was, is bool
+	ok      bool
+}
+
+// transition parses old and new as T and maps them to a state with f.
+func transition[T any](old, new *Resource, f func(T) bool) state {
+	o, err := ParseResource[T](old)
+	if err != nil {
+		return state{}
+	}
+	n, err := ParseResource[T](new)
+	if err != nil {
+		return state{}
+	}
+	return state{was: f(o.Value), is: f(n.Value), ok: true}
+}
+
+// toggle returns on or off if the state changed, and ChangeValue otherwise.
+func toggle(s state, on, off ChangeKind) ChangeKind {
+	switch {
+	case !s.ok || s.was == s.is:
+		return ChangeValue
+	case s.is:
+		return on
+	default:
+		return off
+	}
+}
+
+// trend returns up or down if the numeric value of the resource increased
+// or decreased, and ChangeValue otherwise.
+func trend(old, new *Resource, up, down ChangeKind) ChangeKind {
+	o, err := old.Float()
+	if err != nil {
+		return ChangeValue
+	}
+	n, err := new.Float()
+	if err != nil {
+		return ChangeValue
+	}
+	switch {
+	case n > o:
+		return up
+	case n < o:
+		return down
+	}
+	return ChangeValue
+}
+
+// sameResource reports whether a and b hold the same value, read at the
+// same time.
+func sameResource(a, b *Resource) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equalPtr(a.Value, b.Value) && equalPtr(a.Timestamp, b.Timestamp)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package merche
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resource(value string, timestamp int64) *Resource {
+	return &Resource{Value: String(value), Timestamp: Int64(timestamp)}
+}
+
+func TestDiff(t *testing.T) {
+	old := &VehicleStatus{
+		Doorlockstatusdecklid: resource("false", 1),
+		Doorstatusfrontleft:   resource("false", 1),
+		Doorstatusfrontright:  resource("true", 1),
+		Lightswitchposition:   resource("0", 1),
+		Sunroofstatus:         resource("0", 1),
+		Windowstatusfrontleft: resource("2", 1),
+		Windowstatusrearleft:  resource("0", 1),
+		Windowstatusrearright: resource("1", 1),
+	}
+	new := &VehicleStatus{
+		Doorlockstatusdecklid:  resource("true", 2),
+		Doorstatusfrontleft:    resource("true", 2),
+		Doorstatusfrontright:   resource("false", 2),
+		Lightswitchposition:    resource("0", 2),
+		Rooftopstatus:          resource("2", 2),
+		Sunroofstatus:          resource("0", 1),
+		Windowstatusfrontleft:  resource("1", 2),
+		Windowstatusrearleft:   resource("2", 2),
+		Windowstatusrearright:  resource("0", 2),
+		Windowstatusfrontright: nil,
+	}
+
+	var got []string
+	for _, c := range Diff(old, new) {
+		got = append(got, c.Resource+" "+string(c.Kind))
+	}
+	assert.Equal(t, []string{
+		"doorlockstatusdecklid unlocked",
+		"doorstatusfrontleft door_opened",
+		"doorstatusfrontright door_closed",
+		"lightswitchposition refreshed",
+		"rooftopstatus added",
+		"windowstatusfrontleft window_opened",
+		"windowstatusrearleft window_closed",
+		"windowstatusrearright value_changed",
+	}, got)
+}
+
+func TestDiff_kinds(t *testing.T) {
+	tests := []struct {
+		name string
+		old  *VehicleLockStatus
+		new  *VehicleLockStatus
+		want ChangeKind
+	}{
+		{
+			name: "vehicle locked",
+			old:  &VehicleLockStatus{Doorlockstatusvehicle: resource("0", 1)},
+			new:  &VehicleLockStatus{Doorlockstatusvehicle: resource("2", 2)},
+			want: ChangeLocked,
+		},
+		{
+			name: "lock kind changed",
+			old:  &VehicleLockStatus{Doorlockstatusvehicle: resource("1", 1)},
+			new:  &VehicleLockStatus{Doorlockstatusvehicle: resource("2", 2)},
+			want: ChangeValue,
+		},
+		{
+			name: "gas lid locked",
+			old:  &VehicleLockStatus{Doorlockstatusgas: resource("true", 1)},
+			new:  &VehicleLockStatus{Doorlockstatusgas: resource("false", 2)},
+			want: ChangeLocked,
+		},
+		{
+			name: "removed",
+			old:  &VehicleLockStatus{PositionHeading: resource("10", 1)},
+			new:  nil,
+			want: ChangeRemoved,
+		},
+		{
+			name: "invalid value",
+			old:  &VehicleLockStatus{Doorlockstatusgas: resource("true", 1)},
+			new:  &VehicleLockStatus{Doorlockstatusgas: resource("maybe", 2)},
+			want: ChangeValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(tt.old, tt.new)
+			if assert.Len(t, changes, 1) {
+				assert.Equal(t, tt.want, changes[0].Kind)
+			}
+		})
+	}
+
+	fuel := Diff(
+		&FuelStatus{TankLevelPercent: resource("20", 1), RangeLiquid: resource("200", 1)},
+		&FuelStatus{TankLevelPercent: resource("95", 2), RangeLiquid: resource("150", 2)},
+	)
+	if assert.Len(t, fuel, 2) {
+		assert.Equal(t, ChangeFuelDecreased, fuel[0].Kind)
+		assert.Equal(t, ChangeFuelIncreased, fuel[1].Kind)
+	}
+
+	odo := Diff(&PayAsYouDriveStatus{Odo: resource("100", 1)}, &PayAsYouDriveStatus{Odo: resource("120", 2)})
+	if assert.Len(t, odo, 1) {
+		assert.Equal(t, ChangeOdometerAdvanced, odo[0].Kind)
+	}
+
+	assert.Empty(t, Diff[ElectricVehicleStatus](nil, nil))
+}
+
+func TestResourceChange_json(t *testing.T) {
+	changes := Diff(
+		&PayAsYouDriveStatus{},
+		&PayAsYouDriveStatus{Odo: resource("120", 2)},
+	)
+
+	data, err := json.Marshal(changes)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"resource":"odo","kind":"added","new":{"value":"120","timestamp":2}}]`, string(data))
+}
+
+func Test_diffResources(t *testing.T) {
+	old := map[string]*Resource{
+		"a": {Value: String("1"), Timestamp: Int64(1)},
+		"b": {Value: String("1"), Timestamp: Int64(1)},
+		"c": {Value: String("1"), Timestamp: Int64(1)},
+	}
+	new := map[string]*Resource{
+		"a": {Value: String("1"), Timestamp: Int64(1)},
+		"b": {Value: String("1"), Timestamp: Int64(2)},
+		"d": {Value: String("1"), Timestamp: Int64(2)},
+	}
+
+	changes := diffResources(old, new)
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, "b", changes[0].Resource)
+		assert.Equal(t, "c", changes[1].Resource)
+		assert.Nil(t, changes[1].New)
+		assert.Equal(t, "d", changes[2].Resource)
+		assert.Nil(t, changes[2].Old)
+	}
+}
@@ -2,7 +2,6 @@ package merche
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 )
 
@@ -11,6 +10,16 @@ type ElectricVehicleStatus struct {
 	RangeElectric *Resource `json:"rangeelectric,omitempty"`
 }
 
+// StateOfCharge returns the state of charge of the battery in percent.
+func (s *ElectricVehicleStatus) StateOfCharge() (*TypedResource[int], error) {
+	return ParseResource[int](s.Soc)
+}
+
+// ElectricRange returns the remaining electric range in kilometers.
+func (s *ElectricVehicleStatus) ElectricRange() (*TypedResource[int], error) {
+	return ParseResource[int](s.RangeElectric)
+}
+
 // ElectricVehicleStatusService handles communication with electric vehicle status related
 // methods of the Mercedes API.
 //
@@ -24,7 +33,10 @@ type ElectricVehicleStatusService service
 //
 // Mercedes API docs: https://developer.mercedes-benz.com/products/electric_vehicle_status/specifications/electric_vehicle_status_api
 func (s *ElectricVehicleStatusService) GetElectricVehicleStatus(ctx context.Context, opts *Options) ([]*ElectricVehicleStatus, *Response, error) {
-	path := fmt.Sprintf("%v/%v/containers/electricvehicle", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "containers", string(ContainerElectricVehicleStatus))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -39,3 +51,21 @@ func (s *ElectricVehicleStatusService) GetElectricVehicleStatus(ctx context.Cont
 
 	return status, resp, nil
 }
+
+// GetElectricVehicleStatusSnapshot gets the electric vehicle status of a
+// vehicle like GetElectricVehicleStatus, folding all the resources of the
+// container into a single ElectricVehicleStatus. A *DuplicateResourceError
+// is returned if a resource is received twice.
+func (s *ElectricVehicleStatusService) GetElectricVehicleStatusSnapshot(ctx context.Context, opts *Options) (*Snapshot[ElectricVehicleStatus], *Response, error) {
+	status, resp, err := s.GetElectricVehicleStatus(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	snapshot, err := newSnapshot(status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return snapshot, resp, nil
+}
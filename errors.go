@@ -1,39 +1,177 @@
 package merche
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
+// Sentinel errors matched, using errors.Is, by the errors returned when the
+// Mercedes API answers with an error status code.
+var (
+	ErrBadRequest        = errors.New("bad request")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden: consent missing")
+	ErrVehicleNotFound   = errors.New("vehicle not found")
+	ErrNoDataAvailable   = errors.New("no data available")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrServerUnavailable = errors.New("server unavailable")
+)
+
+// statusError returns the sentinel error of an HTTP status code, if any.
+func statusError(statusCode int) error {
+	switch statusCode {
+	case http.StatusNoContent:
+		return ErrNoDataAvailable
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrVehicleNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrServerUnavailable
+	}
+	return nil
+}
+
+// ErrorResponse holds the details shared by all the errors returned when
+// the Mercedes API answers with an error status code.
+type ErrorResponse struct {
+	// Response is the HTTP response that caused the error. Its body has
+	// already been read and closed.
+	Response *http.Response `json:"-"`
+	// RequestID is the ID assigned to the request by the Mercedes API.
+	RequestID string `json:"-"`
+	// Body is the raw body of the response.
+	Body []byte `json:"-"`
+}
+
+func newErrorResponse(r *http.Response, body []byte) ErrorResponse {
+	requestID := r.Header.Get("X-Request-Id")
+	if requestID == "" {
+		requestID = r.Header.Get("X-Correlation-Id")
+	}
+	return ErrorResponse{Response: r, RequestID: requestID, Body: body}
+}
+
+func (e *ErrorResponse) is(target error) bool {
+	if e.Response == nil {
+		return false
+	}
+	err := statusError(e.Response.StatusCode)
+	return err != nil && err == target
+}
+
 // ExVeError struct for ExVeError.
 type ExVeError struct {
 	ExveErrorID  string `json:"exveErrorId,omitempty"`
 	ExveErrorMsg string `json:"exveErrorMsg,omitempty"`
 	ExveErrorRef string `json:"exveErrorRef,omitempty"`
+
+	ErrorResponse
 }
 
 func (e *ExVeError) Error() string {
 	return fmt.Sprintf("Mercedes API response with %v: %v", e.ExveErrorID, e.ExveErrorMsg)
 }
 
+// Is reports whether target is the sentinel error of the response status.
+func (e *ExVeError) Is(target error) bool { return e.is(target) }
+
+// UnauthorizedError is returned when the access token is missing, invalid
+// or expired.
 type UnauthorizedError struct {
 	ErrorMessage string `json:"errorMessage,omitempty"`
 	StatusCode   string `json:"statusCode,omitempty"`
 	Message      string `json:"message,omitempty"`
+
+	ErrorResponse
 }
 
 func (e *UnauthorizedError) Error() string {
 	return fmt.Sprintf("Mercedes API response with %v: %v", e.StatusCode, e.Message)
 }
 
+// Is reports whether target is ErrUnauthorized.
+func (e *UnauthorizedError) Is(target error) bool { return target == ErrUnauthorized }
+
+// NotFoundError is returned when the vehicle or the resource requested is
+// unknown to the Mercedes API.
+type NotFoundError struct {
+	ErrorResponse
+}
+
+func (e *NotFoundError) Error() string {
+	return "Mercedes API response with 404: vehicle or resource not found"
+}
+
+// Is reports whether target is ErrVehicleNotFound.
+func (e *NotFoundError) Is(target error) bool { return target == ErrVehicleNotFound }
+
+// NoContentError is returned when the Mercedes API has no data available
+// for the requested resources.
+type NoContentError struct {
+	ErrorResponse
+}
+
+func (e *NoContentError) Error() string {
+	return "Mercedes API response with 204: no data available"
+}
+
+// Is reports whether target is ErrNoDataAvailable.
+func (e *NoContentError) Is(target error) bool { return target == ErrNoDataAvailable }
+
+// RateLimitError is returned when the quota of the application has been
+// exceeded.
+type RateLimitError struct {
+	// RetryAfter is the wait time requested by the Mercedes API, if any.
+	RetryAfter time.Duration
+
+	ErrorResponse
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("Mercedes API rate limit exceeded, retry after %v", e.RetryAfter)
+	}
+	return "Mercedes API rate limit exceeded"
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// MercedesAPIError is returned for any other error response of the
+// Mercedes API, including responses whose error body cannot be decoded.
 type MercedesAPIError struct {
 	StatusCode int
+	// Err is the error that occurred decoding the error body, if any.
+	Err error
+
+	ErrorResponse
 }
 
 func (e *MercedesAPIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v: %v", http.StatusText(e.StatusCode), e.Err)
+	}
 	return http.StatusText(e.StatusCode)
 }
 
+// Is reports whether target is the sentinel error of the response status.
+func (e *MercedesAPIError) Is(target error) bool {
+	err := statusError(e.StatusCode)
+	return err != nil && err == target
+}
+
+func (e *MercedesAPIError) Unwrap() error { return e.Err }
+
 func isExVeError(statusCode int) bool {
 	return statusCode == http.StatusBadRequest || statusCode == http.StatusForbidden ||
 		statusCode == http.StatusInternalServerError || statusCode == http.StatusServiceUnavailable
@@ -0,0 +1,149 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_DoErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		header     http.Header
+		wantIs     error
+		wantAs     interface{}
+	}{
+		{
+			name:       "exve bad request",
+			statusCode: http.StatusBadRequest,
+			body:       `{"exveErrorId":"Id","exveErrorMsg":"Msg","exveErrorRef":"Ref"}`,
+			wantIs:     ErrBadRequest,
+			wantAs:     new(*ExVeError),
+		},
+		{
+			name:       "exve missing consent",
+			statusCode: http.StatusForbidden,
+			body:       `{"exveErrorId":"Id","exveErrorMsg":"Msg","exveErrorRef":"Ref"}`,
+			wantIs:     ErrForbidden,
+			wantAs:     new(*ExVeError),
+		},
+		{
+			name:       "exve server unavailable",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"exveErrorId":"Id","exveErrorMsg":"Msg","exveErrorRef":"Ref"}`,
+			wantIs:     ErrServerUnavailable,
+			wantAs:     new(*ExVeError),
+		},
+		{
+			name:       "invalid exve body",
+			statusCode: http.StatusInternalServerError,
+			body:       `<html></html>`,
+			wantIs:     ErrServerUnavailable,
+			wantAs:     new(*MercedesAPIError),
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"errorMessage":"Unauthorized","statusCode":"401","message":"Token invalid: Not active"}`,
+			wantIs:     ErrUnauthorized,
+			wantAs:     new(*UnauthorizedError),
+		},
+		{
+			name:       "invalid unauthorized body",
+			statusCode: http.StatusUnauthorized,
+			wantIs:     ErrUnauthorized,
+			wantAs:     new(*MercedesAPIError),
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			wantIs:     ErrVehicleNotFound,
+			wantAs:     new(*NotFoundError),
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{"60"}},
+			wantIs:     ErrRateLimited,
+			wantAs:     new(*RateLimitError),
+		},
+		{
+			name:       "no content",
+			statusCode: http.StatusNoContent,
+			wantIs:     ErrNoDataAvailable,
+			wantAs:     new(*NoContentError),
+		},
+		{
+			name:       "bad gateway",
+			statusCode: http.StatusBadGateway,
+			wantIs:     ErrServerUnavailable,
+			wantAs:     new(*MercedesAPIError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("X-Request-Id", "request-id")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL + "/")
+
+			c := NewClient(server.Client())
+			c.BaseURL = baseURL
+
+			req, _ := c.NewRequest(context.Background(), http.MethodGet, "", http.NoBody)
+			_, err := c.Do(req, nil)
+
+			assert.ErrorIs(t, err, tt.wantIs)
+			assert.True(t, errors.As(err, tt.wantAs), "error %T is not %T", err, tt.wantAs)
+
+			var errResp interface{ is(error) bool }
+			if assert.True(t, errors.As(err, &errResp)) {
+				assert.True(t, errResp.is(tt.wantIs))
+			}
+		})
+	}
+}
+
+func TestErrorResponse_details(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-id")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("quota exceeded"))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/")
+
+	c := NewClient(server.Client())
+	c.BaseURL = baseURL
+
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "", http.NoBody)
+	_, err := c.Do(req, nil)
+
+	var rateLimitErr *RateLimitError
+	if assert.True(t, errors.As(err, &rateLimitErr)) {
+		assert.Equal(t, 30*time.Second, rateLimitErr.RetryAfter)
+		assert.Equal(t, "request-id", rateLimitErr.RequestID)
+		assert.Equal(t, []byte("quota exceeded"), rateLimitErr.Body)
+		assert.Equal(t, http.StatusTooManyRequests, rateLimitErr.Response.StatusCode)
+	}
+
+	assert.ErrorIs(t, &LimitExceededError{}, ErrRateLimited)
+	assert.False(t, errors.Is(&ExVeError{}, ErrBadRequest), "errors without response match no sentinel")
+}
@@ -1,19 +1,32 @@
 package main
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
 	"log"
 	"net/http"
-	"net/url"
-	"strings"
+	"os"
+	"sync"
+
+	"github.com/jferrl/go-merche"
+	"github.com/jferrl/go-merche/auth"
 )
 
+const stateCookie = "mercedes_oauth_state"
+
 var (
-	clientID     = ""
-	clientSecret = ""
+	config = &auth.Config{
+		ClientID:     os.Getenv("MERCEDES_CLIENT_ID"),
+		ClientSecret: os.Getenv("MERCEDES_CLIENT_SECRET"),
+		RedirectURL:  "http://localhost:3000/login/mercedes/callback",
+		Scopes:       append(merche.ScopesFor(merche.ContainerVehicleStatus), merche.ScopeOfflineAccess),
+	}
+
+	// vehicleID is the VIN of the vehicle read once authorized.
+	vehicleID = os.Getenv("MERCEDES_VEHICLE_ID")
+
+	// pending holds the authorization requests waiting for their callback,
+	// by state.
+	pending sync.Map
 )
 
 func main() {
@@ -26,57 +39,57 @@ func main() {
 }
 
 func mercedesLoginHandler(w http.ResponseWriter, r *http.Request) {
-	redirectURL := fmt.Sprintf(
-		"https://id.mercedes-benz.com/as/authorization.oauth2?response_type=code&client_id=%v&redirect_uri=%v&scope=%v&state=%v",
-		clientID,
-		"http://localhost:3000/login/mercedes/callback",
-		"mb:vehicle:mbdata:vehiclestatus",
-		"login",
-	)
-
-	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	authz, err := config.NewAuthorization()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pending.Store(authz.State, authz)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    authz.State,
+		Path:     "/login/mercedes/",
+		HttpOnly: true,
+	})
+	http.Redirect(w, r, authz.URL, http.StatusTemporaryRedirect)
 }
 
 func mercedesCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	code := r.URL.Query().Get("code")
-
-	getMercedesAccessToken(code)
-}
-
-func getMercedesAccessToken(code string) string {
-	authToken := base64.StdEncoding.EncodeToString([]byte(clientID + ":" + clientSecret))
-
-	data := url.Values{}
-	data.Set("grant_type", "authorization_code")
-	data.Set("redirect_uri", "http://localhost:3000/login/mercedes/callback")
-	data.Set("code", code)
-
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://id.mercedes-benz.com/as/token.oauth2",
-		strings.NewReader(data.Encode()),
-	)
+	cookie, err := r.Cookie(stateCookie)
 	if err != nil {
-		log.Panic("Request creation failed")
+		http.Error(w, "missing authorization request", http.StatusBadRequest)
+		return
+	}
+	authz, ok := pending.LoadAndDelete(cookie.Value)
+	if !ok {
+		http.Error(w, "unknown authorization request", http.StatusBadRequest)
+		return
 	}
-	req.Header.Set("Authorization", "Basic "+authToken)
-	req.Header.Set("content-type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	tok, err := config.Exchange(r.Context(), authz.(*auth.Authorization), r.URL.Query())
 	if err != nil {
-		log.Panic("Request failed")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	body, _ := ioutil.ReadAll(resp.Body)
-
-	type mercedesAccessTokenResponse struct {
-		AccessToken string `json:"access_token"`
-		TokenType   string `json:"token_type"`
-		Scope       string `json:"scope"`
+	client, err := merche.New(
+		merche.WithHTTPClient(config.Client(tok)),
+		merche.WithGrantedScopes(tok.Scopes()...),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	var res mercedesAccessTokenResponse
-	json.Unmarshal(body, &res)
+	status, _, err := client.VehicleStatus.GetVehicleStatusSnapshot(context.Background(), &merche.Options{
+		VehicleID: vehicleID,
+	})
+	if err != nil {
+		log.Printf("token granted, but vehicle status not available: %v", err)
+	} else {
+		log.Printf("vehicle status: %+v", status.Status)
+	}
 
-	return res.AccessToken
+	w.Write([]byte("Authorization completed, you can close this window."))
 }
@@ -0,0 +1,144 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultFleetWorkers is the number of requests sent concurrently by
+// FleetService when FleetOptions.Workers is zero.
+const DefaultFleetWorkers = 4
+
+// FleetOptions specifies the vehicles read by FleetService.
+type FleetOptions struct {
+	VehicleIDs []string
+	// Containers are the containers read for every vehicle. Defaults to all
+	// of them.
+	Containers []Container
+	// Workers is the maximum number of requests in flight at once, across
+	// all the vehicles and their containers. Defaults to
+	// DefaultFleetWorkers.
+	Workers int
+}
+
+func (o *FleetOptions) validate() error {
+	if o == nil {
+		return &ValidationError{Err: ErrNilOptions}
+	}
+	if len(o.VehicleIDs) == 0 {
+		return &ValidationError{Field: "VehicleIDs", Err: errors.New("no vehicle to read")}
+	}
+	if o.Workers < 0 {
+		return &ValidationError{Field: "Workers", Err: fmt.Errorf("workers must not be negative, got %d", o.Workers)}
+	}
+	for _, ct := range o.Containers {
+		if ct.Scope() == "" {
+			return &ValidationError{Field: "Containers", Err: fmt.Errorf("unknown container %q", ct)}
+		}
+	}
+	return nil
+}
+
+// FleetService reads the status of many vehicles concurrently.
+type FleetService service
+
+// GetReports reads the containers of all the vehicles, and returns their
+// reports in the order of opts.VehicleIDs. Vehicles fail independently: the
+// errors of a vehicle are in the Errors of its report. The error returned
+// is either a *ValidationError of opts or the error of ctx, in which case
+// the reports of the vehicles not read yet are nil.
+func (s *FleetService) GetReports(ctx context.Context, opts *FleetOptions) ([]*VehicleReport, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	reports := make([]*VehicleReport, len(opts.VehicleIDs))
+	for r := range s.stream(ctx, opts) {
+		reports[r.index] = r.report
+	}
+	return reports, ctx.Err()
+}
+
+// StreamReports reads the containers of all the vehicles like GetReports,
+// sending every report as soon as it is complete. The channel is closed once
+// all the vehicles have been read or ctx is done.
+func (s *FleetService) StreamReports(ctx context.Context, opts *FleetOptions) (<-chan *VehicleReport, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	reports := make(chan *VehicleReport)
+	go func() {
+		defer close(reports)
+		for r := range s.stream(ctx, opts) {
+			select {
+			case reports <- r.report:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return reports, nil
+}
+
+type fleetReport struct {
+	index  int
+	report *VehicleReport
+}
+
+// stream reads the vehicles with a pool of workers.
+func (s *FleetService) stream(ctx context.Context, opts *FleetOptions) <-chan fleetReport {
+	vins := append([]string(nil), opts.VehicleIDs...)
+	containers := opts.Containers
+	if len(containers) == 0 {
+		containers = Containers()
+	}
+	containers = append([]Container(nil), containers...)
+	workers := opts.Workers
+	if workers == 0 {
+		workers = DefaultFleetWorkers
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range vins {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The slots of limit are shared by the requests of all the vehicles,
+	// including the containers of a vehicle read in parallel.
+	limit := make(chan struct{}, workers)
+
+	results := make(chan fleetReport)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := fleetReport{index: i, report: s.client.vehicleReport(ctx, vins[i], containers, limit)}
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case results <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
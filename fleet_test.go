@@ -0,0 +1,152 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const otherVehicleID = "EXVETESTVIN000002"
+
+func newFleetTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	return c
+}
+
+func TestFleetService_GetReports(t *testing.T) {
+	c := newFleetTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, otherVehicleID):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/fuelstatus"):
+			w.Write([]byte(`[{"tanklevelpercent":{"value":"84","timestamp":1}}]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	reports, err := c.Fleet.GetReports(context.Background(), &FleetOptions{
+		VehicleIDs: []string{fakeVehicleID, otherVehicleID, "123"},
+		Containers: []Container{ContainerFuelStatus, ContainerVehicleLockStatus},
+		Workers:    2,
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, reports, 3) {
+		return
+	}
+
+	assert.Equal(t, fakeVehicleID, reports[0].VehicleID)
+	if assert.NotNil(t, reports[0].FuelStatus) {
+		assert.Equal(t, "84", *reports[0].FuelStatus.TankLevelPercent.Value)
+	}
+	assert.Nil(t, reports[0].VehicleLockStatus)
+	assert.Len(t, reports[0].Errors, 1)
+	assert.ErrorIs(t, reports[0].Errors[ContainerVehicleLockStatus], ErrNoDataAvailable)
+
+	assert.Equal(t, otherVehicleID, reports[1].VehicleID)
+	var notFound *NotFoundError
+	assert.True(t, errors.As(reports[1].Errors[ContainerFuelStatus], &notFound))
+	assert.Len(t, reports[1].Errors, 2)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(reports[2].Errors[ContainerFuelStatus], &validationErr))
+}
+
+func TestFleetService_StreamReports(t *testing.T) {
+	var mu sync.Mutex
+	requests, inFlight, maxInFlight := 0, 0, 0
+
+	c := newFleetTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	vins := []string{fakeVehicleID, otherVehicleID, "EXVETESTVIN000003", "EXVETESTVIN000004"}
+	containers := []Container{ContainerVehicleStatus, ContainerVehicleLockStatus, ContainerFuelStatus}
+	reports, err := c.Fleet.StreamReports(context.Background(), &FleetOptions{
+		VehicleIDs: vins,
+		Containers: containers,
+		Workers:    2,
+	})
+	assert.NoError(t, err)
+
+	got := map[string]bool{}
+	for r := range reports {
+		got[r.VehicleID] = true
+		assert.Len(t, r.Results, len(containers))
+		assert.ErrorIs(t, r.Errors[ContainerVehicleStatus], ErrNoDataAvailable)
+	}
+	assert.Len(t, got, len(vins))
+	assert.Equal(t, len(vins)*len(containers), requests)
+	assert.LessOrEqual(t, maxInFlight, 2)
+}
+
+func TestFleetService_StreamReports_cancel(t *testing.T) {
+	c := newFleetTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reports, err := c.Fleet.StreamReports(ctx, &FleetOptions{
+		VehicleIDs: []string{fakeVehicleID, otherVehicleID},
+		Workers:    1,
+	})
+	assert.NoError(t, err)
+
+	cancel()
+	for range reports {
+	}
+
+	all, err := c.Fleet.GetReports(ctx, &FleetOptions{VehicleIDs: []string{fakeVehicleID}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, all, 1)
+}
+
+func TestFleetOptions_validate(t *testing.T) {
+	c := NewClient(nil)
+
+	tests := []struct {
+		name string
+		opts *FleetOptions
+	}{
+		{name: "nil options"},
+		{name: "no vehicles", opts: &FleetOptions{}},
+		{name: "negative workers", opts: &FleetOptions{VehicleIDs: []string{fakeVehicleID}, Workers: -1}},
+		{name: "unknown container", opts: &FleetOptions{VehicleIDs: []string{fakeVehicleID}, Containers: []Container{"tires"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Fleet.GetReports(context.Background(), tt.opts)
+			var validationErr *ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+
+			_, err = c.Fleet.StreamReports(context.Background(), tt.opts)
+			assert.True(t, errors.As(err, &validationErr))
+		})
+	}
+}
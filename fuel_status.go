@@ -2,7 +2,6 @@ package merche
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 )
 
@@ -12,6 +11,16 @@ type FuelStatus struct {
 	TankLevelPercent *Resource `json:"tanklevelpercent,omitempty"`
 }
 
+// LiquidRange returns the remaining range with the tank fuel in kilometers.
+func (s *FuelStatus) LiquidRange() (*TypedResource[int], error) {
+	return ParseResource[int](s.RangeLiquid)
+}
+
+// TankLevel returns the tank level in percent.
+func (s *FuelStatus) TankLevel() (*TypedResource[int], error) {
+	return ParseResource[int](s.TankLevelPercent)
+}
+
 // FuelStatusService handles communication with fuel status related
 // methods of the Mercedes API.
 //
@@ -25,7 +34,10 @@ type FuelStatusService service
 //
 // Mercedes API docs: https://developer.mercedes-benz.com/products/fuel_status/docs#_3_get_all_values_of_the_fuel_status_api
 func (s *FuelStatusService) GetFuelStatus(ctx context.Context, opts *Options) ([]*FuelStatus, *Response, error) {
-	path := fmt.Sprintf("%v/%v/containers/fuelstatus", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "containers", string(ContainerFuelStatus))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -40,3 +52,21 @@ func (s *FuelStatusService) GetFuelStatus(ctx context.Context, opts *Options) ([
 
 	return status, resp, nil
 }
+
+// GetFuelStatusSnapshot gets the fuel status of a vehicle like
+// GetFuelStatus, folding all the resources of the container into a single
+// FuelStatus. A *DuplicateResourceError is returned if a resource is
+// received twice.
+func (s *FuelStatusService) GetFuelStatusSnapshot(ctx context.Context, opts *Options) (*Snapshot[FuelStatus], *Response, error) {
+	status, resp, err := s.GetFuelStatus(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	snapshot, err := newSnapshot(status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return snapshot, resp, nil
+}
@@ -1,9 +1,26 @@
 package merche
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 // Response is a Mercedes API response. This wraps the standard http.Response
 // returned from Mercedes.
 type Response struct {
 	*http.Response
+
+	// Attempts is the number of attempts made to get the response. It is
+	// zero for a response served from the Cache of the Client, and when the
+	// credentials of the vehicle could not be resolved.
+	Attempts int
+
+	// FromCache reports whether the response has been served from the
+	// Cache of the Client, and Age is then the age of the cached entry.
+	FromCache bool
+	Age       time.Duration
+}
+
+func newResponse(r *http.Response, attempts int) *Response {
+	return &Response{Response: r, Attempts: attempts}
 }
@@ -1,20 +1,24 @@
 package merche
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 const (
 	defaultBaseURL = "https://api.mercedes-benz.com/"
 	userAgent      = "go-merche"
+
+	apiPathPrefix        = "vehicledata/v2/vehicles"
+	sandboxAPIPathPrefix = "vehicledata_tryout/v2/vehicles"
 )
 
 // A Client manages communication with the Mercedes API.
@@ -28,6 +32,32 @@ type Client struct {
 	// User agent used when communicating with the Mercedes API.
 	UserAgent string
 
+	// RetryPolicy defines how failed requests are retried. If nil, every
+	// request is sent exactly once.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter, if set, is waited on before sending each request. It can
+	// be shared by several goroutines using the same Client.
+	RateLimiter Limiter
+
+	// Cache, if set, caches the responses of GET requests.
+	Cache *Cache
+
+	// Credentials, if set, resolves the account authorizing the vehicle of
+	// each request, whose bearer token is set on every attempt. The
+	// http.Client of the Client must then not authorize the requests.
+	Credentials CredentialResolver
+
+	// grantedScopes are the scopes granted to the token of the client, if
+	// known.
+	grantedScopes map[string]bool
+
+	// capabilities caches the capabilities of vehicles, if enabled.
+	capabilities *capabilityCache
+
+	// pathPrefix is the API path of the vehicles of the target environment.
+	pathPrefix string
+
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
 	Resources             *ResourcesService
@@ -36,6 +66,9 @@ type Client struct {
 	VehicleLockStatus     *VehicleLockStatusService
 	FuelStatus            *FuelStatusService
 	PayAsYouDrive         *PayAsYouDriveService
+	Security              *SecurityService
+	Fleet                 *FleetService
+	Capabilities          *CapabilitiesService
 }
 
 type service struct {
@@ -53,9 +86,10 @@ func NewClient(httpClient *http.Client) *Client {
 	baseURL, _ := url.Parse(defaultBaseURL)
 
 	c := &Client{
-		client:    httpClient,
-		BaseURL:   baseURL,
-		UserAgent: userAgent,
+		client:     httpClient,
+		BaseURL:    baseURL,
+		UserAgent:  userAgent,
+		pathPrefix: apiPathPrefix,
 	}
 	c.common.client = c
 
@@ -65,16 +99,36 @@ func NewClient(httpClient *http.Client) *Client {
 	c.VehicleLockStatus = (*VehicleLockStatusService)(&c.common)
 	c.FuelStatus = (*FuelStatusService)(&c.common)
 	c.PayAsYouDrive = (*PayAsYouDriveService)(&c.common)
+	c.Security = (*SecurityService)(&c.common)
+	c.Fleet = (*FleetService)(&c.common)
+	c.Capabilities = (*CapabilitiesService)(&c.common)
 
 	return c
 }
 
+// vehiclePath returns the API path of the given elements of the vehicle
+// of opts, once opts have been validated.
+func (c *Client) vehiclePath(opts *Options, elem ...string) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	return strings.Join(append([]string{c.pathPrefix, opts.VehicleID}, elem...), "/"), nil
+}
+
 // NewRequest creates a Mercedes API request. A path can be provided in path,
 // in which case it is resolved relative to the BaseURL of the Client.
+// If the scopes granted to the Client are known, a *MissingScopeError is
+// returned for requests that require a scope that has not been granted.
 func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	if !strings.HasSuffix(c.BaseURL.Path, "/") {
 		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", c.BaseURL)
 	}
+	if err := c.checkScopes(path); err != nil {
+		return nil, err
+	}
+	if err := c.checkCapabilities(path); err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL.String()+path, body)
 	if err != nil {
@@ -94,16 +148,85 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Re
 // Do sends an API request and lets you handle the api response. If an error
 // or API Error occurs, the error will contain more information. Otherwise you
 // are supposed to read and close the response's Body.
+//
+// Every attempt waits on the RateLimiter of the Client, if any. Failed
+// requests are retried according to the RetryPolicy of the Client. The
+// returned Response reports the number of attempts made.
+//
+// If the Client has a Cache holding a fresh response to req, it is returned
+// without sending req, with FromCache set. The credentials of the vehicle,
+// if any, are resolved first, so that a vehicle without credentials is
+// never served from the Cache.
 func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
+	account, err := c.resolveAccount(req)
+	if err != nil {
+		return newResponse(nil, 0), err
+	}
+
+	if resp, age, ok := c.Cache.lookup(req, account); ok {
+		return &Response{Response: resp, FromCache: true, Age: age}, handleResponse(resp, v)
+	}
+
+	attempts := c.RetryPolicy.maxAttempts(req)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(req, account, v)
+		if attempt >= attempts || !c.RetryPolicy.shouldRetry(resp, err) {
+			return newResponse(resp, attempt), err
+		}
+
+		wait, ok := c.RetryPolicy.backoff(attempt, resp)
+		if !ok {
+			return newResponse(resp, attempt), err
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return newResponse(resp, attempt), err
+		}
+		if req, err = rewind(req); err != nil {
+			return newResponse(resp, attempt), err
+		}
+	}
+}
+
+// do sends a single attempt of an API request, authorized by account if
+// not nil.
+func (c *Client) do(req *http.Request, account *Account, v interface{}) (*http.Response, error) {
+	if c.RateLimiter != nil {
+		t := parseTarget(req.URL.Path)
+		key := LimitKey{VehicleID: t.vehicleID, Container: t.container}
+		if err := c.RateLimiter.Wait(req.Context(), key); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.authorize(req, account)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return &Response{resp}, err
+		return resp, err
 	}
 	defer resp.Body.Close()
 
-	err = checkResponse(resp)
+	if c.Cache.ttl(req) > 0 {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp, err
+		}
+		c.Cache.store(req, account, resp, body)
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, handleResponse(resp, v)
+}
+
+// handleResponse checks resp and decodes its body into v.
+func handleResponse(resp *http.Response, v interface{}) error {
+	err := checkResponse(resp)
 	if err != nil {
-		return &Response{resp}, err
+		return err
 	}
 
 	switch v := v.(type) {
@@ -120,31 +243,44 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 		}
 	}
 
-	return &Response{resp}, err
+	return err
 }
 
 func checkResponse(r *http.Response) error {
+	if r.StatusCode == http.StatusNoContent {
+		return &NoContentError{newErrorResponse(r, nil)}
+	}
 	if code := r.StatusCode; http.StatusOK <= code && code <= 299 {
 		return nil
 	}
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return errors.New("check response: error reading response body")
+		return fmt.Errorf("check response: error reading response body: %w", err)
 	}
+	errResp := newErrorResponse(r, body)
 
-	if isExVeError(r.StatusCode) {
-		var exVeError ExVeError
-		json.Unmarshal(body, &exVeError)
-		return &exVeError
-	}
-	if r.StatusCode == http.StatusUnauthorized {
-		var authErr UnauthorizedError
-		json.Unmarshal(body, &authErr)
-		return &authErr
+	switch {
+	case r.StatusCode == http.StatusUnauthorized:
+		authErr := &UnauthorizedError{ErrorResponse: errResp}
+		if err := json.Unmarshal(body, authErr); err != nil {
+			return &MercedesAPIError{StatusCode: r.StatusCode, Err: err, ErrorResponse: errResp}
+		}
+		return authErr
+	case r.StatusCode == http.StatusNotFound:
+		return &NotFoundError{errResp}
+	case r.StatusCode == http.StatusTooManyRequests:
+		retryAfter, _ := parseRetryAfter(r.Header.Get("Retry-After"), time.Now())
+		return &RateLimitError{RetryAfter: retryAfter, ErrorResponse: errResp}
+	case isExVeError(r.StatusCode):
+		exVeError := &ExVeError{ErrorResponse: errResp}
+		if err := json.Unmarshal(body, exVeError); err != nil {
+			return &MercedesAPIError{StatusCode: r.StatusCode, Err: err, ErrorResponse: errResp}
+		}
+		return exVeError
 	}
 
-	return &MercedesAPIError{r.StatusCode}
+	return &MercedesAPIError{StatusCode: r.StatusCode, ErrorResponse: errResp}
 }
 
 // Bool is a helper routine that allocates a new bool value
@@ -52,10 +52,15 @@ func TestClient_Do(t *testing.T) {
 			},
 		},
 		{
-			name:            "api error",
+			name:            "not found error",
 			mercedesAPIMock: createFakeServer(http.StatusNotFound, ""),
+			wantErr:         &NotFoundError{},
+		},
+		{
+			name:            "api error",
+			mercedesAPIMock: createFakeServer(http.StatusConflict, ""),
 			wantErr: &MercedesAPIError{
-				StatusCode: http.StatusNotFound,
+				StatusCode: http.StatusConflict,
 			},
 		},
 		{
@@ -64,6 +69,7 @@ func TestClient_Do(t *testing.T) {
 			args: args{
 				v: &fakeResponse{},
 			},
+			wantErr: &NoContentError{},
 		},
 		{
 			name:            "mercedes api response: nil decoding",
@@ -89,7 +95,7 @@ func TestClient_Do(t *testing.T) {
 			req, _ := c.NewRequest(context.Background(), http.MethodGet, "", http.NoBody)
 
 			_, err := c.Do(req, tt.args.v)
-			if err != nil && err.Error() != tt.wantErr.Error() {
+			if (err != nil) != (tt.wantErr != nil) || err != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("Client.do() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -0,0 +1,275 @@
+package merchetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// Replay serves the requests from the cassette, without sending them.
+	Replay Mode = iota
+	// Record sends the requests and records them into the cassette.
+	Record
+)
+
+// redacted replaces the value of the headers holding credentials.
+const redacted = "REDACTED"
+
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// UnmatchedRequestError is returned by a replaying Recorder when the
+// cassette holds no interaction for a request.
+type UnmatchedRequestError struct {
+	Method string
+	Path   string
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("merchetest: no recorded interaction for %v %v", e.Method, e.Path)
+}
+
+// Interaction is a request recorded in a cassette, with its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request recorded in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// RecordedResponse is a response recorded in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording the traffic of a Client into a
+// cassette file, and replaying it in tests without credentials:
+//
+//	rec, err := merchetest.NewRecorder("testdata/fuel.json", merchetest.Replay, nil)
+//	client, err := merche.New(merche.WithHTTPClient(&http.Client{Transport: rec}))
+//
+// Credentials are redacted from the cassette and VINs are replaced by
+// pseudonyms. The pseudonym of a VIN is always the same, so a replaying
+// Recorder matches requests for the original VIN too.
+type Recorder struct {
+	mode Mode
+	path string
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	replayed     map[*Interaction]bool
+	vins         map[string]string
+}
+
+// NewRecorder returns a Recorder of the cassette at path. In Record mode,
+// requests are sent with base, or http.DefaultTransport if nil, and the
+// cassette is written by Save. In Replay mode, the cassette is read now.
+func NewRecorder(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		base:     base,
+		replayed: make(map[*Interaction]bool),
+		vins:     make(map[string]string),
+	}
+
+	switch mode {
+	case Record:
+	case Replay:
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("merchetest: error reading cassette: %w", err)
+		}
+		var c cassette
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("merchetest: error decoding cassette %v: %w", path, err)
+		}
+		r.interactions = c.Interactions
+	default:
+		return nil, fmt.Errorf("merchetest: unknown recorder mode %d", mode)
+	}
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == Replay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+// Save writes the recorded interactions to the cassette. It is a no-op in
+// Replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != Record {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(&cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.anonymizePath(req.URL.RequestURI())
+	r.interactions = append(r.interactions, &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   path,
+			Header: r.anonymizeHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.anonymizeHeader(resp.Header),
+			Body:       r.anonymize(string(body)),
+		},
+	})
+	return resp, nil
+}
+
+// replay returns the response of the first interaction matching the
+// method and path of req that has not been replayed yet. Once all of them
+// have been replayed, the last one is replayed again.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.anonymizePath(req.URL.RequestURI())
+
+	var match *Interaction
+	for _, i := range r.interactions {
+		if i.Request.Method != req.Method || i.Request.Path != path {
+			continue
+		}
+		match = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if match == nil {
+		return nil, &UnmatchedRequestError{Method: req.Method, Path: path}
+	}
+	r.replayed[match] = true
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Response.Header.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// anonymizePath replaces the VIN of an API request path by its pseudonym,
+// remembering it to anonymize the rest of the interaction.
+func (r *Recorder) anonymizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "vehicles" {
+			vin := strings.SplitN(segments[i+1], "?", 2)[0]
+			if vin != "" {
+				r.vins[vin] = pseudonym(vin)
+			}
+			break
+		}
+	}
+	return r.anonymize(path)
+}
+
+func (r *Recorder) anonymize(s string) string {
+	for vin, p := range r.vins {
+		s = strings.ReplaceAll(s, vin, p)
+	}
+	return s
+}
+
+func (r *Recorder) anonymizeHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := h[name]; ok {
+			h.Set(name, redacted)
+		}
+	}
+	for name, values := range h {
+		for i, v := range values {
+			values[i] = r.anonymize(v)
+		}
+		h[name] = values
+	}
+	return h
+}
+
+// pseudonymPrefix starts the pseudonyms of VINs. It differs from the prefix
+// of the sandbox vehicles, so a pseudonym never stands for one of them.
+const pseudonymPrefix = "MERCHETEST"
+
+// pseudonymAlphabet holds the characters allowed in a VIN.
+const pseudonymAlphabet = "0123456789ABCDEFGHJKLMNPRSTUVWXYZ"
+
+// pseudonym returns the VIN standing for vin in cassettes. Sandbox VINs are
+// not personal data and, like pseudonyms, are kept as they are.
+func pseudonym(vin string) string {
+	if strings.HasPrefix(vin, "EXVETESTVIN") || strings.HasPrefix(vin, pseudonymPrefix) {
+		return vin
+	}
+	sum := sha256.Sum256([]byte(vin))
+	n := binary.BigEndian.Uint64(sum[:8])
+	b := []byte(pseudonymPrefix + "0000000")
+	for i := len(pseudonymPrefix); i < len(b); i++ {
+		b[i] = pseudonymAlphabet[n%uint64(len(pseudonymAlphabet))]
+		n /= uint64(len(pseudonymAlphabet))
+	}
+	return string(b)
+}
@@ -0,0 +1,118 @@
+package merchetest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jferrl/go-merche"
+	"github.com/jferrl/go-merche/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+const realVIN = "WDD2221821A123456"
+
+func recorderClient(t *testing.T, baseURL string, rt http.RoundTripper, opts ...merche.ClientOption) *merche.Client {
+	t.Helper()
+
+	hc := auth.NewClient(auth.StaticTokenSource(&auth.Token{AccessToken: "secret-token"}))
+	hc.Transport.(*auth.Transport).Base = rt
+
+	c, err := merche.New(append([]merche.ClientOption{merche.WithBaseURL(baseURL), merche.WithHTTPClient(hc)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: realVIN}
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := NewServer()
+	s.AddVehicle(realVIN, StaticVehicle{
+		"tanklevelpercent": Value("84"),
+		"odo":              Value("319947"),
+	})
+	s.Authorize("secret-token", merche.ScopesFor(merche.Containers()...)...)
+
+	rec, err := NewRecorder(cassette, Record, s.Server.Client().Transport)
+	assert.NoError(t, err)
+	c := recorderClient(t, s.URL+"/", rec)
+
+	want, _, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.NoError(t, err)
+	metas, _, err := c.Resources.GetAvailableResources(ctx, opts)
+	assert.NoError(t, err)
+	assert.Contains(t, *metas[0].Href, realVIN, "recorded responses are returned unchanged")
+	_, _, err = c.ElectricVehicleStatus.GetElectricVehicleStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrNoDataAvailable)
+
+	assert.NoError(t, rec.Save())
+	s.Close()
+
+	data, err := ioutil.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), realVIN)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.Contains(t, string(data), pseudonym(realVIN))
+
+	rec, err = NewRecorder(cassette, Replay, nil)
+	assert.NoError(t, err)
+	c = recorderClient(t, s.URL+"/", rec)
+
+	got, _, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	metas, _, err = c.Resources.GetAvailableResources(ctx, &merche.Options{VehicleID: pseudonym(realVIN)})
+	assert.NoError(t, err)
+	assert.Contains(t, *metas[0].Href, pseudonym(realVIN))
+
+	_, _, err = c.ElectricVehicleStatus.GetElectricVehicleStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrNoDataAvailable)
+
+	_, _, err = c.PayAsYouDrive.GetPayAsYouDriveStatus(ctx, opts)
+	var unmatched *UnmatchedRequestError
+	if assert.True(t, errors.As(err, &unmatched)) {
+		assert.Equal(t, http.MethodGet, unmatched.Method)
+		assert.Equal(t, "/vehicledata/v2/vehicles/"+pseudonym(realVIN)+"/containers/payasyoudrive", unmatched.Path)
+	}
+}
+
+func TestRecorder_unmatchedNotRetried(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	assert.NoError(t, ioutil.WriteFile(cassette, []byte(`{"interactions":[]}`), 0o644))
+
+	rec, err := NewRecorder(cassette, Replay, nil)
+	assert.NoError(t, err)
+	c := recorderClient(t, "https://api.mercedes-benz.com/", rec, merche.WithRetryPolicy(merche.DefaultRetryPolicy()))
+
+	_, resp, err := c.FuelStatus.GetFuelStatus(context.Background(), &merche.Options{VehicleID: realVIN})
+	var unmatched *UnmatchedRequestError
+	assert.True(t, errors.As(err, &unmatched))
+	assert.Equal(t, 1, resp.Attempts)
+}
+
+func TestNewRecorder(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), Replay, nil)
+	assert.Error(t, err)
+
+	_, err = NewRecorder("", Mode(7), nil)
+	assert.Error(t, err)
+}
+
+func Test_pseudonym(t *testing.T) {
+	p := pseudonym(realVIN)
+	assert.Equal(t, p, pseudonym(realVIN))
+	assert.NotEqual(t, p, pseudonym("WDD2221821A654321"))
+	assert.NoError(t, merche.ValidateVIN(p))
+	assert.True(t, strings.HasPrefix(p, pseudonymPrefix), "pseudonyms must not collide with sandbox VINs")
+	assert.Equal(t, p, pseudonym(p))
+	assert.Equal(t, "EXVETESTVIN000001", pseudonym("EXVETESTVIN000001"))
+}
@@ -0,0 +1,468 @@
+// Package merchetest provides an in-process fake of the Mercedes API for
+// tests of code built on go-merche.
+//
+// The fake Server serves the container and resource endpoints of the
+// vehicles added to it, checks the bearer token and scopes of every request,
+// injects faults per route and records the requests received:
+//
+//	s := merchetest.NewServer()
+//	defer s.Close()
+//
+//	s.AddVehicle("EXVETESTVIN000001", merchetest.StaticVehicle{
+//		"rangeliquid": merchetest.Value("1648"),
+//	})
+//	s.Authorize("token", merche.ScopesFor(merche.Containers()...)...)
+//
+//	client, err := s.Client("token")
+package merchetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jferrl/go-merche"
+	"github.com/jferrl/go-merche/auth"
+)
+
+// Vehicle provides the resources served for a vehicle.
+type Vehicle interface {
+	// Resources returns the current value of the resources of the vehicle,
+	// by resource name.
+	Resources() map[string]*merche.Resource
+}
+
+// StaticVehicle is a Vehicle whose resources never change.
+type StaticVehicle map[string]*merche.Resource
+
+// Resources implements the Vehicle interface.
+func (v StaticVehicle) Resources() map[string]*merche.Resource { return v }
+
+// Value returns a resource holding v, updated now.
+func Value(v string) *merche.Resource {
+	return &merche.Resource{Value: merche.String(v), Timestamp: merche.Int64(time.Now().UnixMilli())}
+}
+
+// Fault is an error response, or a delay, injected by the Server.
+type Fault struct {
+	// StatusCode is the status of the response. If zero, the request is
+	// only delayed by Latency and then served normally.
+	StatusCode int
+	// ExVeErrorID, if set, is returned in an ExVe error body.
+	ExVeErrorID string
+	// RetryAfter, if positive, is sent in the Retry-After header.
+	RetryAfter time.Duration
+	// Latency delays the response.
+	Latency time.Duration
+	// Times is the number of requests affected by the fault. Zero means
+	// every request.
+	Times int
+}
+
+// ExVeFault returns a Fault responding with an ExVe error.
+func ExVeFault(statusCode int, id string) Fault {
+	return Fault{StatusCode: statusCode, ExVeErrorID: id}
+}
+
+// UnauthorizedFault returns a Fault responding as if the token had expired.
+func UnauthorizedFault() Fault {
+	return Fault{StatusCode: http.StatusUnauthorized}
+}
+
+// RateLimitFault returns a Fault responding as if the quota had been
+// exceeded.
+func RateLimitFault(retryAfter time.Duration) Fault {
+	return Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// LatencyFault returns a Fault delaying the responses by d.
+func LatencyFault(d time.Duration) Fault {
+	return Fault{Latency: d}
+}
+
+// Request is a request received by the Server.
+type Request struct {
+	Method    string
+	Path      string
+	VehicleID string
+	Container merche.Container
+	Resource  string
+	Token     string
+	Header    http.Header
+	// StatusCode is the status the Server responded with.
+	StatusCode int
+}
+
+type routeFault struct {
+	route string
+	fault Fault
+	hits  int
+}
+
+// Server is a fake Mercedes API. It serves both the production and the
+// sandbox paths.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	vehicles map[string]Vehicle
+	tokens   map[string]map[string]bool
+	faults   []*routeFault
+	requests []Request
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		vehicles: make(map[string]Vehicle),
+		tokens:   make(map[string]map[string]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddVehicle adds, or replaces, the vehicle served for vin.
+func (s *Server) AddVehicle(vin string, v Vehicle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vehicles[vin] = v
+}
+
+// Authorize accepts token as a bearer token granted the given scopes.
+// Requests with any other token are rejected with 401.
+func (s *Server) Authorize(token string, scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	granted := make(map[string]bool)
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	s.tokens[token] = granted
+}
+
+// Revoke rejects token from now on.
+func (s *Server) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, token)
+}
+
+// Inject injects f into the responses of route. The route is a path.Match
+// pattern matched against the path following the VIN, like
+// "containers/fuelstatus", "resources" or "resources/*". An empty route
+// matches every request. Faults are applied in the order injected.
+func (s *Server) Inject(route string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &routeFault{route: route, fault: f})
+}
+
+// ClearFaults removes all the injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// ClearRequests forgets the requests received so far.
+func (s *Server) ClearRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// Client returns a merche.Client sending requests to the Server with
+// token. opts are applied after the options pointing the client to the
+// Server.
+func (s *Server) Client(token string, opts ...merche.ClientOption) (*merche.Client, error) {
+	hc := *s.Server.Client()
+	hc.Transport = &auth.Transport{
+		Source: auth.StaticTokenSource(&auth.Token{AccessToken: token, TokenType: "Bearer"}),
+		Base:   hc.Transport,
+	}
+
+	return merche.New(append([]merche.ClientOption{
+		merche.WithBaseURL(s.URL + "/"),
+		merche.WithHTTPClient(&hc),
+	}, opts...)...)
+}
+
+// route describes the vehicle data addressed by a request.
+type route struct {
+	vehicleID string
+	container merche.Container
+	resource  string
+	// path is the path following the VIN.
+	path string
+}
+
+func parseRoute(p string) (route, bool) {
+	var r route
+
+	p = strings.Trim(p, "/")
+	for _, prefix := range []string{"vehicledata/v2/vehicles/", "vehicledata_tryout/v2/vehicles/"} {
+		if rest := strings.TrimPrefix(p, prefix); rest != p {
+			vin, rest, _ := strings.Cut(rest, "/")
+			r.vehicleID, r.path = vin, rest
+
+			segments := strings.Split(rest, "/")
+			switch {
+			case len(segments) == 2 && segments[0] == "containers":
+				r.container = merche.Container(segments[1])
+			case len(segments) == 2 && segments[0] == "resources":
+				r.resource = segments[1]
+			case len(segments) == 1 && segments[0] == "resources":
+			default:
+				return r, false
+			}
+			return r, vin != ""
+		}
+	}
+	return r, false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, ok := parseRoute(r.URL.Path)
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	rec := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, Request{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			VehicleID:  rt.vehicleID,
+			Container:  rt.container,
+			Resource:   rt.resource,
+			Token:      token,
+			Header:     r.Header.Clone(),
+			StatusCode: rec.statusCode,
+		})
+	}()
+
+	if !ok {
+		writeNotFound(rec)
+		return
+	}
+
+	if f, ok := s.fault(rt); ok {
+		if f.Latency > 0 {
+			t := time.NewTimer(f.Latency)
+			select {
+			case <-t.C:
+			case <-r.Context().Done():
+				t.Stop()
+				return
+			}
+		}
+		if f.StatusCode != 0 {
+			writeFault(rec, f)
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		writeJSON(rec, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	s.mu.Lock()
+	scopes, authorized := s.tokens[token]
+	vehicle := s.vehicles[rt.vehicleID]
+	s.mu.Unlock()
+
+	if !authorized || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeFault(rec, UnauthorizedFault())
+		return
+	}
+	if vehicle == nil {
+		writeNotFound(rec)
+		return
+	}
+
+	resources := vehicle.Resources()
+	switch {
+	case rt.container != "":
+		s.serveContainer(rec, rt.container, scopes, resources)
+	case rt.resource != "":
+		s.serveResource(rec, rt, scopes, resources)
+	default:
+		s.serveResources(rec, rt.vehicleID, scopes, resources)
+	}
+}
+
+// fault returns the first fault injected into the route that still
+// applies.
+func (s *Server) fault(rt route) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.faults {
+		if f.route != "" {
+			if ok, _ := path.Match(f.route, rt.path); !ok {
+				continue
+			}
+		}
+		if f.fault.Times > 0 && f.hits >= f.fault.Times {
+			continue
+		}
+		f.hits++
+		return f.fault, true
+	}
+	return Fault{}, false
+}
+
+func (s *Server) serveContainer(w http.ResponseWriter, c merche.Container, scopes map[string]bool, resources map[string]*merche.Resource) {
+	if c.Scope() == "" {
+		writeNotFound(w)
+		return
+	}
+	if !scopes[c.Scope()] {
+		writeFault(w, Fault{StatusCode: http.StatusForbidden})
+		return
+	}
+
+	var body []map[string]*merche.Resource
+	for _, name := range c.Resources() {
+		if r, ok := resources[name]; ok && r != nil {
+			body = append(body, map[string]*merche.Resource{name: r})
+		}
+	}
+	if len(body) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (s *Server) serveResource(w http.ResponseWriter, rt route, scopes map[string]bool, resources map[string]*merche.Resource) {
+	cs := containersOf(rt.resource)
+	if len(cs) == 0 {
+		writeNotFound(w)
+		return
+	}
+	if !granted(scopes, cs) {
+		writeFault(w, Fault{StatusCode: http.StatusForbidden})
+		return
+	}
+
+	r, ok := resources[rt.resource]
+	if !ok || r == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]*merche.Resource{rt.resource: r})
+}
+
+func (s *Server) serveResources(w http.ResponseWriter, vin string, scopes map[string]bool, resources map[string]*merche.Resource) {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		if cs := containersOf(name); len(cs) > 0 && granted(scopes, cs) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	body := make([]*merche.ResourceMetaInfo, len(names))
+	for i, name := range names {
+		body[i] = &merche.ResourceMetaInfo{
+			Href:    merche.String("/vehicles/" + vin + "/resources/" + name),
+			Name:    merche.String(name),
+			Version: merche.String("1.0"),
+		}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// containersOf returns the containers holding a resource.
+func containersOf(resource string) []merche.Container {
+	var cs []merche.Container
+	for _, c := range merche.Containers() {
+		for _, r := range c.Resources() {
+			if r == resource {
+				cs = append(cs, c)
+			}
+		}
+	}
+	return cs
+}
+
+// granted reports whether the scope of any of the containers is granted.
+func granted(scopes map[string]bool, cs []merche.Container) bool {
+	for _, c := range cs {
+		if scopes[c.Scope()] {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFault(w http.ResponseWriter, f Fault) {
+	if f.RetryAfter > 0 {
+		secs := int((f.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+
+	switch {
+	case f.ExVeErrorID != "":
+		writeJSON(w, f.StatusCode, map[string]string{
+			"exveErrorId":  f.ExVeErrorID,
+			"exveErrorMsg": http.StatusText(f.StatusCode),
+			"exveErrorRef": "merchetest",
+		})
+	case f.StatusCode == http.StatusUnauthorized:
+		writeJSON(w, f.StatusCode, map[string]string{
+			"errorMessage": "Unauthorized",
+			"statusCode":   "401",
+			"message":      "Token invalid: Not active",
+		})
+	default:
+		writeJSON(w, f.StatusCode, map[string]string{"message": http.StatusText(f.StatusCode)})
+	}
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// statusRecorder records the status of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
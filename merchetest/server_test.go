@@ -0,0 +1,223 @@
+package merchetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jferrl/go-merche"
+	"github.com/stretchr/testify/assert"
+)
+
+const vin = "EXVETESTVIN000001"
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	s.AddVehicle(vin, StaticVehicle{
+		"rangeliquid":           Value("1648"),
+		"tanklevelpercent":      Value("84"),
+		"doorlockstatusdecklid": Value("false"),
+		"odo":                   Value("319947"),
+	})
+	s.Authorize("all", merche.ScopesFor(merche.Containers()...)...)
+	s.Authorize("fuel", merche.ScopeFuelStatus)
+	return s
+}
+
+func newTestClient(t *testing.T, s *Server, token string, opts ...merche.ClientOption) *merche.Client {
+	t.Helper()
+
+	c, err := s.Client(token, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestServer_containers(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s, "all")
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: vin}
+
+	status, _, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.NoError(t, err)
+	assert.Len(t, status, 2)
+
+	snapshot, _, err := c.FuelStatus.GetFuelStatusSnapshot(ctx, opts)
+	assert.NoError(t, err)
+	tank, _ := snapshot.Status.TankLevel()
+	assert.Equal(t, 84, tank.Value)
+
+	_, _, err = c.ElectricVehicleStatus.GetElectricVehicleStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrNoDataAvailable)
+
+	_, _, err = c.FuelStatus.GetFuelStatus(ctx, &merche.Options{VehicleID: "EXVETESTVIN000002"})
+	assert.ErrorIs(t, err, merche.ErrVehicleNotFound)
+
+	sandbox := newTestClient(t, s, "all", merche.WithEnvironment(merche.Sandbox))
+	_, _, err = sandbox.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.NoError(t, err)
+}
+
+func TestServer_resources(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s, "all")
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: vin}
+
+	metas, _, err := c.Resources.GetAvailableResources(ctx, opts)
+	assert.NoError(t, err)
+	assert.Len(t, metas, 4)
+
+	odo, _, err := c.Resources.FollowResource(ctx, metas[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "odo", odo.Name)
+	assert.Equal(t, "319947", *odo.Value)
+
+	_, _, err = c.Resources.GetResource(ctx, opts, "soc")
+	assert.ErrorIs(t, err, merche.ErrNoDataAvailable)
+
+	_, _, err = c.Resources.GetResource(ctx, opts, "unknown")
+	assert.ErrorIs(t, err, merche.ErrVehicleNotFound)
+}
+
+func TestServer_authorization(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: vin}
+
+	_, _, err := newTestClient(t, s, "unknown").FuelStatus.GetFuelStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrUnauthorized)
+
+	fuel := newTestClient(t, s, "fuel")
+	_, _, err = fuel.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.NoError(t, err)
+
+	_, _, err = fuel.PayAsYouDrive.GetPayAsYouDriveStatus(ctx, opts)
+	var exveErr *merche.ExVeError
+	assert.True(t, errors.As(err, &exveErr))
+	assert.ErrorIs(t, err, merche.ErrForbidden)
+
+	metas, _, err := fuel.Resources.GetAvailableResources(ctx, opts)
+	assert.NoError(t, err)
+	assert.Len(t, metas, 2)
+
+	s.Revoke("fuel")
+	_, _, err = fuel.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrUnauthorized)
+}
+
+func TestServer_Inject(t *testing.T) {
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: vin}
+
+	tests := []struct {
+		name    string
+		route   string
+		fault   Fault
+		wantErr error
+	}{
+		{
+			name:    "exve error",
+			route:   "containers/fuelstatus",
+			fault:   ExVeFault(http.StatusBadRequest, "104"),
+			wantErr: merche.ErrBadRequest,
+		},
+		{
+			name:    "unauthorized",
+			route:   "containers/*",
+			fault:   UnauthorizedFault(),
+			wantErr: merche.ErrUnauthorized,
+		},
+		{
+			name:    "rate limited",
+			fault:   RateLimitFault(2 * time.Second),
+			wantErr: merche.ErrRateLimited,
+		},
+		{
+			name:  "other route",
+			route: "resources/*",
+			fault: UnauthorizedFault(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.Inject(tt.route, tt.fault)
+
+			_, _, err := newTestClient(t, s, "all").FuelStatus.GetFuelStatus(ctx, opts)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("retry after", func(t *testing.T) {
+		s := newTestServer(t)
+		s.Inject("", RateLimitFault(1500*time.Millisecond))
+
+		_, _, err := newTestClient(t, s, "all").FuelStatus.GetFuelStatus(ctx, opts)
+		var rateErr *merche.RateLimitError
+		if assert.True(t, errors.As(err, &rateErr)) {
+			assert.Equal(t, 2*time.Second, rateErr.RetryAfter)
+		}
+	})
+
+	t.Run("times", func(t *testing.T) {
+		s := newTestServer(t)
+		f := ExVeFault(http.StatusServiceUnavailable, "503")
+		f.Times = 1
+		s.Inject("containers/fuelstatus", f)
+
+		policy := merche.DefaultRetryPolicy()
+		policy.BaseBackoff = time.Millisecond
+		c := newTestClient(t, s, "all", merche.WithRetryPolicy(policy))
+
+		_, resp, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Attempts)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		s := newTestServer(t)
+		s.Inject("", LatencyFault(time.Second))
+
+		c := newTestClient(t, s, "all", merche.WithTimeout(50*time.Millisecond))
+		_, _, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+		assert.Error(t, err)
+
+		s.ClearFaults()
+		_, _, err = c.FuelStatus.GetFuelStatus(ctx, opts)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServer_Requests(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s, "all")
+	ctx := context.Background()
+
+	c.FuelStatus.GetFuelStatus(ctx, &merche.Options{VehicleID: vin})
+	c.Resources.GetResource(ctx, &merche.Options{VehicleID: vin}, "odo")
+
+	reqs := s.Requests()
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, vin, reqs[0].VehicleID)
+		assert.Equal(t, merche.ContainerFuelStatus, reqs[0].Container)
+		assert.Equal(t, "all", reqs[0].Token)
+		assert.Equal(t, http.StatusOK, reqs[0].StatusCode)
+		assert.Equal(t, "odo", reqs[1].Resource)
+	}
+
+	s.ClearRequests()
+	assert.Empty(t, s.Requests())
+}
@@ -0,0 +1,448 @@
+package merchetest
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jferrl/go-merche"
+)
+
+// Clock tells the time of a Simulator.
+type Clock = merche.Clock
+
+// ManualClock is a Clock that only moves when told to.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now implements the Clock interface.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Powertrain is the kind of drive of a simulated vehicle.
+type Powertrain = merche.Powertrain
+
+const (
+	// ICE is a vehicle with an internal combustion engine.
+	ICE = merche.PowertrainICE
+	// BEV is a battery electric vehicle.
+	BEV = merche.PowertrainBEV
+	// PHEV is a plug-in hybrid electric vehicle. It drives on electricity
+	// until its battery is empty.
+	PHEV = merche.PowertrainPHEV
+)
+
+func hasTank(p Powertrain) bool    { return p == ICE || p == PHEV }
+func hasBattery(p Powertrain) bool { return p == BEV || p == PHEV }
+
+// Position is the position of a door or a window.
+type Position string
+
+// Door and window positions.
+const (
+	FrontLeft  Position = "frontleft"
+	FrontRight Position = "frontright"
+	RearLeft   Position = "rearleft"
+	RearRight  Position = "rearright"
+)
+
+var positions = []Position{FrontLeft, FrontRight, RearLeft, RearRight}
+
+// Ranges of the simulated vehicles, in km.
+const (
+	tankRange      = 800
+	bevRange       = 450
+	phevRange      = 50
+	initialOdo     = 10000
+	initialPercent = 80
+)
+
+// Simulator is a Vehicle whose resources change over time as it plays the
+// scripted steps given to Run. The resources are evaluated at the time of
+// its Clock when read, so that a vehicle halfway through a Drive reports
+// half of the distance driven.
+type Simulator struct {
+	powertrain Powertrain
+	clock      Clock
+
+	mu        sync.Mutex
+	odometer  float64
+	tank      float64
+	soc       float64
+	resources map[string]*merche.Resource
+
+	steps     []Step
+	stepStart time.Time
+	started   bool
+	progress  float64
+}
+
+// NewSimulator returns a parked and locked vehicle with the powertrain p,
+// running on clock.
+func NewSimulator(p Powertrain, clock Clock) *Simulator {
+	s := &Simulator{
+		powertrain: p,
+		clock:      clock,
+		odometer:   initialOdo,
+		resources:  make(map[string]*merche.Resource),
+	}
+
+	now := clock.Now()
+	if hasTank(p) {
+		s.tank = initialPercent
+		s.set("doorlockstatusgas", "false", now)
+	}
+	if hasBattery(p) {
+		s.soc = initialPercent
+	}
+	for _, pos := range positions {
+		s.set("doorstatus"+string(pos), "false", now)
+		s.set("windowstatus"+string(pos), code(merche.WindowClosed), now)
+	}
+	s.set("doorlockstatusvehicle", code(merche.DoorLockExternalLocked), now)
+	s.set("doorlockstatusdecklid", "false", now)
+	s.set("lightswitchposition", code(merche.LightSwitchAuto), now)
+	s.set("interiorLightsFront", "false", now)
+	s.set("interiorLightsRear", "false", now)
+	s.set("positionHeading", "0", now)
+	s.update(now)
+	return s
+}
+
+// Powertrain returns the powertrain of the vehicle.
+func (s *Simulator) Powertrain() Powertrain { return s.powertrain }
+
+// Run schedules steps to be played after the steps already scheduled. If
+// the vehicle is idle, the first step starts now.
+func (s *Simulator) Run(steps ...Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance(s.clock.Now())
+	if len(s.steps) == 0 {
+		s.stepStart = s.clock.Now()
+	}
+	s.steps = append(s.steps, steps...)
+}
+
+// Idle reports whether all the scheduled steps have been played.
+func (s *Simulator) Idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance(s.clock.Now())
+	return len(s.steps) == 0
+}
+
+// Resources implements the Vehicle interface.
+func (s *Simulator) Resources() map[string]*merche.Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance(s.clock.Now())
+
+	resources := make(map[string]*merche.Resource, len(s.resources))
+	for name, r := range s.resources {
+		resources[name] = &merche.Resource{
+			Value:     merche.String(*r.Value),
+			Timestamp: merche.Int64(*r.Timestamp),
+		}
+	}
+	return resources
+}
+
+// advance plays the scheduled steps up to now.
+func (s *Simulator) advance(now time.Time) {
+	for len(s.steps) > 0 {
+		step := s.steps[0]
+		if now.Before(s.stepStart) {
+			return
+		}
+		if !s.started {
+			if step.begin != nil {
+				step.begin(s, s.stepStart)
+			}
+			s.started = true
+		}
+
+		end := s.stepStart.Add(step.duration)
+		at, progress := now, 1.0
+		if now.Before(end) {
+			progress = float64(now.Sub(s.stepStart)) / float64(step.duration)
+		} else {
+			at = end
+		}
+		if step.progress != nil && progress > s.progress {
+			step.progress(s, progress-s.progress, at)
+		}
+		s.progress = progress
+
+		if progress < 1 {
+			return
+		}
+		if step.end != nil {
+			step.end(s, end)
+		}
+		s.steps = s.steps[1:]
+		s.stepStart, s.started, s.progress = end, false, 0
+	}
+}
+
+// set sets the value of a resource, updating its timestamp if it changed.
+func (s *Simulator) set(name, value string, at time.Time) {
+	if r, ok := s.resources[name]; ok && *r.Value == value {
+		return
+	}
+	s.resources[name] = &merche.Resource{
+		Value:     merche.String(value),
+		Timestamp: merche.Int64(at.UnixMilli()),
+	}
+}
+
+// update renders the odometer, tank and battery into their resources.
+func (s *Simulator) update(at time.Time) {
+	s.set("odo", strconv.Itoa(int(s.odometer)), at)
+	if hasTank(s.powertrain) {
+		s.set("tanklevelpercent", strconv.Itoa(int(math.Round(s.tank))), at)
+		s.set("rangeliquid", strconv.Itoa(int(s.tank*tankRange/100)), at)
+	}
+	if hasBattery(s.powertrain) {
+		s.set("soc", strconv.Itoa(int(math.Round(s.soc))), at)
+		s.set("rangeelectric", strconv.Itoa(int(s.soc*s.electricRange()/100)), at)
+	}
+}
+
+func (s *Simulator) electricRange() float64 {
+	if s.powertrain == PHEV {
+		return phevRange
+	}
+	return bevRange
+}
+
+// drive drives km, draining the battery first.
+func (s *Simulator) drive(km float64, at time.Time) {
+	s.odometer += km
+	if hasBattery(s.powertrain) {
+		electric := math.Min(km, s.soc*s.electricRange()/100)
+		if s.powertrain == BEV {
+			electric = km
+		}
+		s.soc = math.Max(0, s.soc-electric*100/s.electricRange())
+		km -= electric
+	}
+	if hasTank(s.powertrain) {
+		s.tank = math.Max(0, s.tank-km*100/tankRange)
+	}
+	s.update(at)
+}
+
+func code(c interface{ MarshalText() ([]byte, error) }) string {
+	b, _ := c.MarshalText()
+	return string(b)
+}
+
+// Step is a step of the script played by a Simulator.
+type Step struct {
+	name     string
+	duration time.Duration
+
+	begin func(s *Simulator, at time.Time)
+	// progress is called with the fraction of the step played since the
+	// last call.
+	progress func(s *Simulator, delta float64, at time.Time)
+	end      func(s *Simulator, at time.Time)
+}
+
+func (s Step) String() string { return s.name }
+
+// Duration returns the time it takes to play the step.
+func (s Step) Duration() time.Duration { return s.duration }
+
+// Duration returns the time it takes to play steps.
+func Duration(steps ...Step) time.Duration {
+	var d time.Duration
+	for _, step := range steps {
+		d += step.duration
+	}
+	return d
+}
+
+// Wait is a step where nothing happens for d.
+func Wait(d time.Duration) Step {
+	return Step{name: "wait", duration: d}
+}
+
+// Drive is a step driving km in d. The doors are closed and locked from
+// the inside while driving.
+func Drive(km float64, d time.Duration) Step {
+	return Step{
+		name:     "drive",
+		duration: d,
+		begin: func(s *Simulator, at time.Time) {
+			for _, pos := range positions {
+				s.set("doorstatus"+string(pos), "false", at)
+			}
+			s.set("doorlockstatusvehicle", code(merche.DoorLockInternalLocked), at)
+			s.set("lightswitchposition", code(merche.LightSwitchHeadlights), at)
+		},
+		progress: func(s *Simulator, delta float64, at time.Time) {
+			s.drive(km*delta, at)
+		},
+	}
+}
+
+// Park is a step closing the doors and locking the vehicle. The windows
+// are left as they are.
+func Park() Step {
+	return Step{
+		name: "park",
+		end: func(s *Simulator, at time.Time) {
+			for _, pos := range positions {
+				s.set("doorstatus"+string(pos), "false", at)
+			}
+			s.set("doorlockstatusvehicle", code(merche.DoorLockExternalLocked), at)
+			s.set("doorlockstatusdecklid", "false", at)
+			s.set("lightswitchposition", code(merche.LightSwitchAuto), at)
+		},
+	}
+}
+
+// Unlock is a step unlocking the vehicle.
+func Unlock() Step {
+	return Step{
+		name: "unlock",
+		end: func(s *Simulator, at time.Time) {
+			s.set("doorlockstatusvehicle", code(merche.DoorLockUnlocked), at)
+			s.set("doorlockstatusdecklid", "true", at)
+		},
+	}
+}
+
+// OpenDoor is a step opening the door at p.
+func OpenDoor(p Position) Step {
+	return Step{
+		name: "open door " + string(p),
+		end: func(s *Simulator, at time.Time) {
+			s.set("doorstatus"+string(p), "true", at)
+		},
+	}
+}
+
+// OpenWindow is a step opening the window at p, which stays open until
+// closed with CloseWindow.
+func OpenWindow(p Position) Step {
+	return Step{
+		name: "open window " + string(p),
+		end: func(s *Simulator, at time.Time) {
+			s.set("windowstatus"+string(p), code(merche.WindowOpen), at)
+		},
+	}
+}
+
+// CloseWindow is a step closing the window at p.
+func CloseWindow(p Position) Step {
+	return Step{
+		name: "close window " + string(p),
+		end: func(s *Simulator, at time.Time) {
+			s.set("windowstatus"+string(p), code(merche.WindowClosed), at)
+		},
+	}
+}
+
+// Charge is a step charging the battery up to percent in d. It only waits
+// for vehicles without a battery.
+func Charge(percent float64, d time.Duration) Step {
+	var from float64
+	return Step{
+		name:     "charge",
+		duration: d,
+		begin: func(s *Simulator, _ time.Time) {
+			from = s.soc
+		},
+		progress: func(s *Simulator, delta float64, at time.Time) {
+			if !hasBattery(s.powertrain) || percent <= from {
+				return
+			}
+			s.soc = math.Min(percent, s.soc+(percent-from)*delta)
+			s.update(at)
+		},
+	}
+}
+
+// Refuel is a step filling up the tank in d, with the gas lid unlocked
+// meanwhile. It only waits for vehicles without a tank.
+func Refuel(d time.Duration) Step {
+	var from float64
+	return Step{
+		name:     "refuel",
+		duration: d,
+		begin: func(s *Simulator, at time.Time) {
+			from = s.tank
+			if hasTank(s.powertrain) {
+				s.set("doorlockstatusgas", "true", at)
+			}
+		},
+		progress: func(s *Simulator, delta float64, at time.Time) {
+			if !hasTank(s.powertrain) {
+				return
+			}
+			s.tank = math.Min(100, s.tank+(100-from)*delta)
+			s.update(at)
+		},
+		end: func(s *Simulator, at time.Time) {
+			if hasTank(s.powertrain) {
+				s.set("doorlockstatusgas", "false", at)
+			}
+		},
+	}
+}
+
+// RandomScenario returns n trips of random length, each followed by a
+// random stop in which the vehicle may be charged, refueled or left with a
+// window open.
+func RandomScenario(r *rand.Rand, p Powertrain, n int) []Step {
+	var steps []Step
+	for i := 0; i < n; i++ {
+		km := 5 + r.Float64()*75
+		speed := 30 + r.Float64()*70
+		steps = append(steps,
+			Unlock(),
+			OpenDoor(FrontLeft),
+			Drive(km, time.Duration(km/speed*float64(time.Hour))),
+			Park(),
+		)
+
+		switch x := r.Intn(10); {
+		case x < 3 && hasBattery(p):
+			steps = append(steps, Charge(100, 2*time.Hour+time.Duration(r.Intn(6))*time.Hour))
+		case x < 5 && hasTank(p):
+			steps = append(steps, Refuel(5*time.Minute))
+		case x == 9:
+			pos := positions[r.Intn(len(positions))]
+			steps = append(steps, OpenWindow(pos), Wait(time.Duration(1+r.Intn(8))*time.Hour), CloseWindow(pos))
+		}
+		steps = append(steps, Wait(time.Duration(1+r.Intn(12))*time.Hour))
+	}
+	return steps
+}
@@ -0,0 +1,170 @@
+package merchetest
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jferrl/go-merche"
+	"github.com/stretchr/testify/assert"
+)
+
+var simStart = time.Date(2022, 8, 1, 8, 0, 0, 0, time.UTC)
+
+func value(t *testing.T, v Vehicle, name string) string {
+	t.Helper()
+
+	r, ok := v.Resources()[name]
+	if !ok {
+		t.Fatalf("resource %q missing", name)
+	}
+	return *r.Value
+}
+
+func TestSimulator_Drive(t *testing.T) {
+	clock := NewManualClock(simStart)
+	sim := NewSimulator(ICE, clock)
+	sim.Run(Drive(100, time.Hour), Park())
+
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, "10050", value(t, sim, "odo"))
+	assert.Equal(t, code(merche.DoorLockInternalLocked), value(t, sim, "doorlockstatusvehicle"))
+	odo := sim.Resources()["odo"]
+	assert.Equal(t, simStart.Add(30*time.Minute).UnixMilli(), *odo.Timestamp)
+	assert.False(t, sim.Idle())
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, "10100", value(t, sim, "odo"))
+	assert.Equal(t, "68", value(t, sim, "tanklevelpercent"))
+	assert.Equal(t, "540", value(t, sim, "rangeliquid"))
+	assert.Equal(t, code(merche.DoorLockExternalLocked), value(t, sim, "doorlockstatusvehicle"))
+	odo = sim.Resources()["odo"]
+	assert.Equal(t, simStart.Add(time.Hour).UnixMilli(), *odo.Timestamp, "timestamp of the last change")
+	assert.True(t, sim.Idle())
+}
+
+func TestSimulator_powertrains(t *testing.T) {
+	tests := []struct {
+		powertrain Powertrain
+		km         float64
+		want       map[string]string
+		missing    []string
+	}{
+		{
+			powertrain: ICE,
+			km:         80,
+			want:       map[string]string{"tanklevelpercent": "70"},
+			missing:    []string{"soc", "rangeelectric"},
+		},
+		{
+			powertrain: BEV,
+			km:         45,
+			want:       map[string]string{"soc": "70", "rangeelectric": "315"},
+			missing:    []string{"tanklevelpercent", "rangeliquid", "doorlockstatusgas"},
+		},
+		{
+			powertrain: PHEV,
+			km:         120,
+			want:       map[string]string{"soc": "0", "rangeelectric": "0", "tanklevelpercent": "70"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.powertrain.String(), func(t *testing.T) {
+			clock := NewManualClock(simStart)
+			sim := NewSimulator(tt.powertrain, clock)
+			sim.Run(Drive(tt.km, time.Hour))
+			clock.Advance(time.Hour)
+
+			resources := sim.Resources()
+			for name, want := range tt.want {
+				assert.Equal(t, want, *resources[name].Value, name)
+			}
+			for _, name := range tt.missing {
+				assert.NotContains(t, resources, name)
+			}
+		})
+	}
+}
+
+func TestSimulator_stops(t *testing.T) {
+	clock := NewManualClock(simStart)
+	sim := NewSimulator(PHEV, clock)
+	sim.Run(
+		Drive(120, 2*time.Hour),
+		Refuel(10*time.Minute),
+		Charge(100, 4*time.Hour),
+		OpenWindow(RearLeft),
+	)
+
+	clock.Advance(2*time.Hour + 5*time.Minute)
+	assert.Equal(t, "85", value(t, sim, "tanklevelpercent"))
+	assert.Equal(t, "true", value(t, sim, "doorlockstatusgas"))
+
+	clock.Advance(5 * time.Minute)
+	assert.Equal(t, "100", value(t, sim, "tanklevelpercent"))
+	assert.Equal(t, "false", value(t, sim, "doorlockstatusgas"))
+
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, "50", value(t, sim, "soc"))
+	assert.Equal(t, code(merche.WindowClosed), value(t, sim, "windowstatusrearleft"))
+
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, "100", value(t, sim, "soc"))
+	assert.Equal(t, code(merche.WindowOpen), value(t, sim, "windowstatusrearleft"))
+}
+
+func TestRandomScenario(t *testing.T) {
+	steps := RandomScenario(rand.New(rand.NewSource(1)), BEV, 20)
+	again := RandomScenario(rand.New(rand.NewSource(1)), BEV, 20)
+	if assert.Len(t, again, len(steps)) {
+		for i := range steps {
+			assert.Equal(t, steps[i].String(), again[i].String())
+			assert.Equal(t, steps[i].Duration(), again[i].Duration())
+		}
+	}
+
+	clock := NewManualClock(simStart)
+	sim := NewSimulator(BEV, clock)
+	sim.Run(steps...)
+
+	last := 0
+	for end := simStart.Add(Duration(steps...)); clock.Now().Before(end); clock.Advance(15 * time.Minute) {
+		odo, err := strconv.Atoi(value(t, sim, "odo"))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, odo, last)
+		last = odo
+	}
+	clock.Advance(time.Minute)
+	assert.True(t, sim.Idle())
+	assert.Greater(t, last, initialOdo)
+}
+
+func TestSimulator_server(t *testing.T) {
+	clock := NewManualClock(simStart)
+	sim := NewSimulator(BEV, clock)
+
+	s := NewServer()
+	defer s.Close()
+	s.AddVehicle(vin, sim)
+	s.Authorize("token", merche.ScopesFor(merche.Containers()...)...)
+
+	c, err := s.Client("token")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	opts := &merche.Options{VehicleID: vin}
+
+	sim.Run(Drive(45, time.Hour))
+	clock.Advance(time.Hour)
+
+	snapshot, _, err := c.ElectricVehicleStatus.GetElectricVehicleStatusSnapshot(ctx, opts)
+	assert.NoError(t, err)
+	soc, err := snapshot.Status.StateOfCharge()
+	assert.NoError(t, err)
+	assert.Equal(t, 70, soc.Value)
+	assert.True(t, soc.Timestamp.Equal(simStart.Add(time.Hour)))
+
+	_, _, err = c.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.ErrorIs(t, err, merche.ErrNoDataAvailable)
+}
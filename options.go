@@ -1,6 +1,129 @@
 package merche
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	vinLength = 17
+
+	// sandboxVINPrefix is the prefix of the test vehicles served by the
+	// Mercedes sandbox, like EXVETESTVIN000001.
+	sandboxVINPrefix = "EXVETESTVIN"
+)
+
+// ErrNilOptions is returned when a request is made with nil Options.
+var ErrNilOptions = errors.New("options must not be nil")
+
 // Options defines the input for each API request.
 type Options struct {
 	VehicleID string
 }
+
+// Validate reports whether the options can be used to build a request.
+// Errors are of type *ValidationError.
+func (o *Options) Validate() error {
+	if o == nil {
+		return &ValidationError{Err: ErrNilOptions}
+	}
+	if err := ValidateVIN(o.VehicleID); err != nil {
+		return &ValidationError{Field: "VehicleID", Err: err}
+	}
+	return nil
+}
+
+// ValidationError is returned when the Options of a request are invalid.
+// No request is sent to the Mercedes API.
+type ValidationError struct {
+	// Field is the name of the invalid field, if any.
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid options: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid options: %v: %v", e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// VINError is returned when a vehicle identification number is invalid.
+type VINError struct {
+	VIN    string
+	Reason string
+}
+
+func (e *VINError) Error() string {
+	return fmt.Sprintf("invalid VIN %q: %v", e.VIN, e.Reason)
+}
+
+// ValidateVIN checks a vehicle identification number as defined by
+// ISO 3779: 17 characters, digits and capital letters except I, O and Q.
+// The check digit is verified for North American VINs, where it is
+// mandatory. The Mercedes sandbox IDs, like EXVETESTVIN000001, are valid.
+func ValidateVIN(vin string) error {
+	if len(vin) != vinLength {
+		return &VINError{VIN: vin, Reason: fmt.Sprintf("must have %d characters, has %d", vinLength, len(vin))}
+	}
+
+	if strings.HasPrefix(vin, sandboxVINPrefix) {
+		for _, r := range vin[len(sandboxVINPrefix):] {
+			if r < '0' || r > '9' {
+				return &VINError{VIN: vin, Reason: "sandbox vehicle IDs must end with digits"}
+			}
+		}
+		return nil
+	}
+
+	for i, r := range vin {
+		if vinValue(r) < 0 {
+			return &VINError{VIN: vin, Reason: fmt.Sprintf("invalid character %q at position %d", r, i+1)}
+		}
+	}
+
+	if vin[0] >= '1' && vin[0] <= '5' {
+		if want := vinCheckDigit(vin); vin[8] != want {
+			return &VINError{VIN: vin, Reason: fmt.Sprintf("check digit is %q, want %q", vin[8], want)}
+		}
+	}
+
+	return nil
+}
+
+var vinWeights = [vinLength]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// vinCheckDigit computes the check digit, the 9th character, of a VIN.
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i, r := range vin {
+		sum += vinValue(r) * vinWeights[i]
+	}
+	if rem := sum % 11; rem < 10 {
+		return byte('0' + rem)
+	}
+	return 'X'
+}
+
+// vinValue returns the transliterated value of a VIN character, or -1 if
+// the character is not allowed.
+func vinValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'A' && r <= 'H':
+		return int(r-'A') + 1
+	case r >= 'J' && r <= 'N':
+		return int(r-'J') + 1
+	case r == 'P':
+		return 7
+	case r == 'R':
+		return 9
+	case r >= 'S' && r <= 'Z':
+		return int(r-'S') + 2
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		wantErr bool
+	}{
+		{name: "sandbox vehicle", vin: "EXVETESTVIN000001"},
+		{name: "european vehicle", vin: "WDD2220821A123456"},
+		{name: "north american vehicle", vin: "1M8GDM9AXKP042788"},
+		{name: "empty", vin: "", wantErr: true},
+		{name: "too short", vin: "WDD2220821A12345", wantErr: true},
+		{name: "lower case", vin: "wdd2220821a123456", wantErr: true},
+		{name: "forbidden letter", vin: "WDD2220821O123456", wantErr: true},
+		{name: "path traversal", vin: "WDD2220821A/../12", wantErr: true},
+		{name: "wrong check digit", vin: "1M8GDM9A1KP042788", wantErr: true},
+		{name: "sandbox vehicle with letters", vin: "EXVETESTVIN00000A", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVIN(tt.vin)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateVIN() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				var vinErr *VINError
+				assert.True(t, errors.As(err, &vinErr))
+			}
+		})
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	var nilOpts *Options
+	assert.ErrorIs(t, nilOpts.Validate(), ErrNilOptions)
+
+	err := (&Options{VehicleID: "invalid"}).Validate()
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Equal(t, "VehicleID", validationErr.Field)
+	}
+	assert.EqualError(t, err, `invalid options: VehicleID: invalid VIN "invalid": must have 17 characters, has 7`)
+
+	assert.NoError(t, (&Options{VehicleID: fakeVehicleID}).Validate())
+}
+
+func TestServices_invalidOptions(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/")
+
+	c := NewClient(server.Client())
+	c.BaseURL = baseURL
+
+	ctx := context.Background()
+	calls := map[string]func(opts *Options) error{
+		"GetAvailableResources": func(opts *Options) error {
+			_, _, err := c.Resources.GetAvailableResources(ctx, opts)
+			return err
+		},
+		"GetResource": func(opts *Options) error {
+			_, _, err := c.Resources.GetResource(ctx, opts, "odo")
+			return err
+		},
+		"GetVehicleStatus": func(opts *Options) error {
+			_, _, err := c.VehicleStatus.GetVehicleStatus(ctx, opts)
+			return err
+		},
+		"GetVehicleLockStatus": func(opts *Options) error {
+			_, _, err := c.VehicleLockStatus.GetVehicleLockStatus(ctx, opts)
+			return err
+		},
+		"GetFuelStatus": func(opts *Options) error {
+			_, _, err := c.FuelStatus.GetFuelStatus(ctx, opts)
+			return err
+		},
+		"GetElectricVehicleStatus": func(opts *Options) error {
+			_, _, err := c.ElectricVehicleStatus.GetElectricVehicleStatus(ctx, opts)
+			return err
+		},
+		"GetPayAsYouDriveStatus": func(opts *Options) error {
+			_, _, err := c.PayAsYouDrive.GetPayAsYouDriveStatus(ctx, opts)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			var validationErr *ValidationError
+			assert.True(t, errors.As(call(nil), &validationErr))
+			assert.True(t, errors.As(call(&Options{}), &validationErr))
+		})
+	}
+
+	_, _, err := c.Resources.GetResource(ctx, &Options{VehicleID: fakeVehicleID}, "")
+	assert.Error(t, err)
+
+	assert.Zero(t, requests)
+}
@@ -2,7 +2,6 @@ package merche
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 )
 
@@ -11,6 +10,11 @@ type PayAsYouDriveStatus struct {
 	Odo *Resource `json:"odo,omitempty"`
 }
 
+// Odometer returns the odometer reading in kilometers.
+func (s *PayAsYouDriveStatus) Odometer() (*TypedResource[int], error) {
+	return ParseResource[int](s.Odo)
+}
+
 // PayAsYouDriveService handles communication with vehicle status related
 // methods of the Mercedes API.
 
@@ -26,7 +30,10 @@ type PayAsYouDriveService service
 //
 // Mercedes API docs: https://developer.mercedes-benz.com/products/pay_as_you_drive_insurance/docs#_3_get_all_values_of_the_pay_as_you_drive_insurance_api
 func (s *PayAsYouDriveService) GetPayAsYouDriveStatus(ctx context.Context, opts *Options) ([]*PayAsYouDriveStatus, *Response, error) {
-	path := fmt.Sprintf("%v/%v/containers/payasyoudrive", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "containers", string(ContainerPayAsYouDrive))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -41,3 +48,21 @@ func (s *PayAsYouDriveService) GetPayAsYouDriveStatus(ctx context.Context, opts
 
 	return status, resp, nil
 }
+
+// GetPayAsYouDriveStatusSnapshot gets the pay as you drive status of a
+// vehicle like GetPayAsYouDriveStatus, folding all the resources of the
+// container into a single PayAsYouDriveStatus. A *DuplicateResourceError is
+// returned if a resource is received twice.
+func (s *PayAsYouDriveService) GetPayAsYouDriveStatusSnapshot(ctx context.Context, opts *Options) (*Snapshot[PayAsYouDriveStatus], *Response, error) {
+	status, resp, err := s.GetPayAsYouDriveStatus(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	snapshot, err := newSnapshot(status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return snapshot, resp, nil
+}
@@ -0,0 +1,175 @@
+package merche
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limiter limits the rate of requests sent to the Mercedes API. Wait blocks
+// until a request addressed to key is allowed or ctx is done.
+//
+// A Limiter must be safe for concurrent use.
+type Limiter interface {
+	Wait(ctx context.Context, key LimitKey) error
+}
+
+// LimitKey identifies the quota buckets a request is accounted against.
+type LimitKey struct {
+	VehicleID string
+	// Container is empty for requests that do not target a container.
+	Container Container
+}
+
+// Rate defines a token bucket: Requests are allowed every Per period, with
+// bursts of up to Burst requests.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+	// Burst is the bucket size. Values lower than 1 are treated as 1.
+	Burst int
+}
+
+// LimitExceededError is returned by a fail fast RateLimiter when a request
+// is not allowed yet.
+type LimitExceededError struct {
+	Key LimitKey
+	// RetryIn is the time after which the request would have been allowed.
+	RetryIn time.Duration
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("client rate limit exceeded for vehicle %q, retry in %v", e.Key.VehicleID, e.RetryIn)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *LimitExceededError) Is(target error) bool { return target == ErrRateLimited }
+
+// RateLimiter is a Limiter that accounts every request against a global
+// token bucket, a bucket per vehicle and a bucket per container. A nil Rate
+// disables the corresponding bucket.
+//
+// The zero value allows every request. The configuration fields must not be
+// modified once the RateLimiter is in use.
+type RateLimiter struct {
+	Global       *Rate
+	PerVehicle   *Rate
+	PerContainer *Rate
+
+	// FailFast makes Wait return a *LimitExceededError instead of blocking
+	// when a request is not allowed yet.
+	FailFast bool
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// Wait implements the Limiter interface.
+func (l *RateLimiter) Wait(ctx context.Context, key LimitKey) error {
+	for {
+		wait := l.reserve(key)
+		if wait == 0 {
+			return nil
+		}
+		if l.FailFast {
+			return &LimitExceededError{Key: key, RetryIn: wait}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token from every bucket key is accounted against. If any
+// of them is empty no token is taken and the time until all of them have a
+// token is returned.
+func (l *RateLimiter) reserve(key LimitKey) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+
+	var buckets []*bucket
+	if l.Global != nil {
+		buckets = append(buckets, l.bucket("global", l.Global))
+	}
+	if l.PerVehicle != nil && key.VehicleID != "" {
+		buckets = append(buckets, l.bucket("vehicle/"+key.VehicleID, l.PerVehicle))
+	}
+	if l.PerContainer != nil && key.Container != "" {
+		buckets = append(buckets, l.bucket("container/"+string(key.Container), l.PerContainer))
+	}
+
+	var wait time.Duration
+	for _, b := range buckets {
+		if d := b.refill(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+func (l *RateLimiter) bucket(name string, r *Rate) *bucket {
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	b, ok := l.buckets[name]
+	if !ok {
+		b = newBucket(r)
+		l.buckets[name] = b
+	}
+	return b
+}
+
+type bucket struct {
+	tokens float64
+	size   float64
+	// interval is the time needed to refill one token.
+	interval time.Duration
+	last     time.Time
+}
+
+func newBucket(r *Rate) *bucket {
+	size := float64(r.Burst)
+	if size < 1 {
+		size = 1
+	}
+	var interval time.Duration
+	if r.Requests > 0 {
+		interval = r.Per / time.Duration(r.Requests)
+	}
+	return &bucket{tokens: size, size: size, interval: interval}
+}
+
+// refill adds the tokens generated since the last refill and returns the
+// time until a token is available.
+func (b *bucket) refill(now time.Time) time.Duration {
+	if b.interval <= 0 {
+		b.tokens = b.size
+		return 0
+	}
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+		if b.tokens > b.size {
+			b.tokens = b.size
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
@@ -0,0 +1,100 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	l := &RateLimiter{
+		Global:       &Rate{Requests: 10, Per: time.Second, Burst: 10},
+		PerVehicle:   &Rate{Requests: 1, Per: time.Minute},
+		PerContainer: &Rate{Requests: 2, Per: time.Minute, Burst: 2},
+		FailFast:     true,
+		now:          func() time.Time { return now },
+	}
+	ctx := context.Background()
+	fuel := LimitKey{VehicleID: fakeVehicleID, Container: ContainerFuelStatus}
+
+	assert.NoError(t, l.Wait(ctx, fuel))
+
+	var limitErr *LimitExceededError
+	err := l.Wait(ctx, fuel)
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, fuel, limitErr.Key)
+		assert.Equal(t, time.Minute, limitErr.RetryIn)
+	}
+
+	assert.NoError(t, l.Wait(ctx, LimitKey{VehicleID: "EXVETESTVIN000002", Container: ContainerFuelStatus}))
+	assert.Error(t, l.Wait(ctx, LimitKey{VehicleID: "EXVETESTVIN000003", Container: ContainerFuelStatus}),
+		"per container bucket must be exhausted")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, l.Wait(ctx, fuel))
+}
+
+func TestRateLimiter_WaitContextCanceled(t *testing.T) {
+	l := &RateLimiter{Global: &Rate{Requests: 1, Per: time.Hour}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, l.Wait(ctx, LimitKey{}))
+	assert.ErrorIs(t, l.Wait(ctx, LimitKey{}), context.DeadlineExceeded)
+}
+
+func TestClient_DoRateLimited(t *testing.T) {
+	server := createFakeServer(http.StatusOK, "fuel_status_get_containers.json")
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/")
+
+	c := NewClient(server.Client())
+	c.BaseURL = baseURL
+	c.RateLimiter = &RateLimiter{PerVehicle: &Rate{Requests: 1, Per: time.Hour}, FailFast: true}
+
+	opts := &Options{VehicleID: fakeVehicleID}
+
+	_, _, err := c.FuelStatus.GetFuelStatus(context.Background(), opts)
+	assert.NoError(t, err)
+
+	_, _, err = c.FuelStatus.GetFuelStatus(context.Background(), opts)
+	var limitErr *LimitExceededError
+	assert.True(t, errors.As(err, &limitErr))
+}
+
+func Test_parseTarget(t *testing.T) {
+	tests := []struct {
+		path string
+		want target
+	}{
+		{
+			path: "/vehicledata/v2/vehicles/EXVETESTVIN000001/containers/fuelstatus",
+			want: target{vehicleID: fakeVehicleID, container: ContainerFuelStatus},
+		},
+		{
+			path: "/vehicledata/v2/vehicles/EXVETESTVIN000001/resources/odo",
+			want: target{vehicleID: fakeVehicleID, resource: "odo"},
+		},
+		{
+			path: "/vehicledata/v2/vehicles/EXVETESTVIN000001/resources",
+			want: target{vehicleID: fakeVehicleID},
+		},
+		{
+			path: "/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTarget(tt.path))
+		})
+	}
+}
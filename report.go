@@ -0,0 +1,219 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ReadStatus is the outcome of reading a container of a vehicle.
+type ReadStatus string
+
+// Outcomes of reading a container.
+const (
+	// ReadOK means that the container has been read.
+	ReadOK ReadStatus = "ok"
+	// ReadNoData means that the container has no data available.
+	ReadNoData ReadStatus = "no_data"
+	// ReadNotAvailable means that the vehicle does not offer the container,
+	// like the Electric Vehicle Status of a combustion car: the Mercedes API
+	// answered 404 Not Found, or the detected capabilities of the vehicle
+	// lack the container.
+	ReadNotAvailable ReadStatus = "not_available"
+	// ReadVehicleNotFound means that the Mercedes API does not know the
+	// vehicle, like a mistyped VIN: every container requested was answered
+	// with 404 Not Found.
+	ReadVehicleNotFound ReadStatus = "vehicle_not_found"
+	// ReadForbidden means that the scope of the container has not been
+	// granted.
+	ReadForbidden ReadStatus = "forbidden"
+	// ReadFailed means that the container could not be read for any other
+	// reason.
+	ReadFailed ReadStatus = "failed"
+)
+
+// readStatus returns the ReadStatus of the error of a read.
+func readStatus(err error) ReadStatus {
+	switch {
+	case err == nil:
+		return ReadOK
+	case errors.Is(err, ErrNoDataAvailable):
+		return ReadNoData
+	case errors.Is(err, ErrVehicleNotFound), errors.Is(err, ErrUnsupported):
+		return ReadNotAvailable
+	case errors.Is(err, ErrForbidden):
+		return ReadForbidden
+	}
+	return ReadFailed
+}
+
+// ContainerResult is the outcome of reading a container of a vehicle.
+type ContainerResult struct {
+	Container Container
+	Status    ReadStatus
+	Err       error
+	// Response is the one of the last attempt, or nil if no request was
+	// sent.
+	Response *Response
+}
+
+// VehicleReport holds the status of a vehicle read out of several
+// containers. The status of a container that could not be read is nil, and
+// its error is in Errors.
+type VehicleReport struct {
+	VehicleID string
+
+	VehicleStatus         *VehicleStatus
+	VehicleLockStatus     *VehicleLockStatus
+	FuelStatus            *FuelStatus
+	ElectricVehicleStatus *ElectricVehicleStatus
+	PayAsYouDriveStatus   *PayAsYouDriveStatus
+
+	// Results holds the outcome of every container read.
+	Results map[Container]*ContainerResult
+	// Errors holds the error of every container that could not be read. It
+	// is nil if all of them were read.
+	Errors map[Container]error
+}
+
+// GetVehicleReport reads the given containers of a vehicle in parallel, or
+// all of them if none is given. Containers fail independently: the outcome
+// of each one is in the Results of the report, so that a combustion car
+// without Electric Vehicle Status or a missing scope does not fail the
+// others. With WithCapabilityDetection, the containers the vehicle does
+// not offer are not requested. The error returned is a *ValidationError of
+// opts or containers.
+func (c *Client) GetVehicleReport(ctx context.Context, opts *Options, containers ...Container) (*VehicleReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	for _, ct := range containers {
+		if ct.Scope() == "" {
+			return nil, &ValidationError{Field: "Containers", Err: fmt.Errorf("unknown container %q", ct)}
+		}
+	}
+	if len(containers) == 0 {
+		containers = Containers()
+	}
+	return c.vehicleReport(ctx, opts.VehicleID, containers, nil), nil
+}
+
+// vehicleReport reads the containers of a vehicle in parallel into a
+// VehicleReport. If limit is not nil, every request holds a slot of it
+// while in flight, bounding the requests sent concurrently.
+func (c *Client) vehicleReport(ctx context.Context, vehicleID string, containers []Container, limit chan struct{}) *VehicleReport {
+	r := &VehicleReport{VehicleID: vehicleID, Results: make(map[Container]*ContainerResult)}
+	opts := &Options{VehicleID: vehicleID}
+
+	if c.capabilities != nil && acquire(ctx, limit) {
+		// Detecting the capabilities first skips the containers the
+		// vehicle does not offer. If it fails, all of them are read.
+		c.Capabilities.GetCapabilities(ctx, opts)
+		release(limit)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, ct := range containers {
+		wg.Add(1)
+		go func(ct Container) {
+			defer wg.Done()
+
+			var set func(*VehicleReport)
+			var resp *Response
+			err := ctx.Err()
+			if acquire(ctx, limit) {
+				set, resp, err = c.readContainer(ctx, opts, ct)
+				release(limit)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			r.Results[ct] = &ContainerResult{Container: ct, Status: readStatus(err), Err: err, Response: resp}
+			if err != nil {
+				if r.Errors == nil {
+					r.Errors = make(map[Container]error)
+				}
+				r.Errors[ct] = err
+				return
+			}
+			set(r)
+		}(ct)
+	}
+	wg.Wait()
+
+	if vehicleNotFound(r) {
+		for _, result := range r.Results {
+			if result.Response != nil {
+				result.Status = ReadVehicleNotFound
+			}
+		}
+	}
+	return r
+}
+
+// vehicleNotFound reports whether the Mercedes API answered 404 Not Found to
+// all the requests of a report. A single container answered so may just not
+// be offered by the vehicle, so more than one request must have been sent.
+func vehicleNotFound(r *VehicleReport) bool {
+	var sent int
+	for _, result := range r.Results {
+		if result.Response == nil {
+			continue
+		}
+		if result.Status != ReadNotAvailable {
+			return false
+		}
+		sent++
+	}
+	return sent > 1
+}
+
+// acquire takes a slot of limit, waiting for one to be free. It reports
+// false if ctx is done first. A nil limit has unlimited slots.
+func acquire(ctx context.Context, limit chan struct{}) bool {
+	if limit == nil {
+		return true
+	}
+	select {
+	case limit <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees a slot of limit taken with acquire.
+func release(limit chan struct{}) {
+	if limit != nil {
+		<-limit
+	}
+}
+
+// readContainer reads a container, returning a func that sets its status
+// in a VehicleReport.
+func (c *Client) readContainer(ctx context.Context, opts *Options, ct Container) (func(*VehicleReport), *Response, error) {
+	switch ct {
+	case ContainerVehicleStatus:
+		return read(ctx, opts, c.VehicleStatus.GetVehicleStatusSnapshot, func(r *VehicleReport) **VehicleStatus { return &r.VehicleStatus })
+	case ContainerVehicleLockStatus:
+		return read(ctx, opts, c.VehicleLockStatus.GetVehicleLockStatusSnapshot, func(r *VehicleReport) **VehicleLockStatus { return &r.VehicleLockStatus })
+	case ContainerFuelStatus:
+		return read(ctx, opts, c.FuelStatus.GetFuelStatusSnapshot, func(r *VehicleReport) **FuelStatus { return &r.FuelStatus })
+	case ContainerElectricVehicleStatus:
+		return read(ctx, opts, c.ElectricVehicleStatus.GetElectricVehicleStatusSnapshot, func(r *VehicleReport) **ElectricVehicleStatus { return &r.ElectricVehicleStatus })
+	case ContainerPayAsYouDrive:
+		return read(ctx, opts, c.PayAsYouDrive.GetPayAsYouDriveStatusSnapshot, func(r *VehicleReport) **PayAsYouDriveStatus { return &r.PayAsYouDriveStatus })
+	}
+	return nil, nil, fmt.Errorf("unknown container %q", ct)
+}
+
+func read[T any](ctx context.Context, opts *Options, get func(context.Context, *Options) (*Snapshot[T], *Response, error), field func(*VehicleReport) **T) (func(*VehicleReport), *Response, error) {
+	snapshot, resp, err := get(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+	return func(r *VehicleReport) { *field(r) = snapshot.Status }, resp, nil
+}
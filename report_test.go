@@ -0,0 +1,119 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetVehicleReport(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/fuelstatus"):
+			w.Write([]byte(`[{"tanklevelpercent":{"value":"84","timestamp":1}}]`))
+		case strings.HasSuffix(r.URL.Path, "/vehiclelockstatus"):
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/electricvehicle"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithGrantedScopes(ScopeFuelStatus, ScopeVehicleLockStatus, ScopeElectricVehicleStatus, ScopeVehicleStatus),
+	)
+	assert.NoError(t, err)
+
+	report, err := c.GetVehicleReport(context.Background(), &Options{VehicleID: fakeVehicleID})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeVehicleID, report.VehicleID)
+	assert.Len(t, paths, 4)
+
+	wantStatus := map[Container]ReadStatus{
+		ContainerFuelStatus:            ReadOK,
+		ContainerVehicleLockStatus:     ReadNoData,
+		ContainerElectricVehicleStatus: ReadNotAvailable,
+		ContainerPayAsYouDrive:         ReadForbidden,
+		ContainerVehicleStatus:         ReadFailed,
+	}
+	for ct, want := range wantStatus {
+		if assert.Contains(t, report.Results, ct) {
+			assert.Equal(t, want, report.Results[ct].Status, ct)
+		}
+	}
+
+	if assert.NotNil(t, report.FuelStatus) {
+		assert.Equal(t, "84", *report.FuelStatus.TankLevelPercent.Value)
+	}
+	assert.Nil(t, report.Results[ContainerFuelStatus].Err)
+	assert.Equal(t, http.StatusOK, report.Results[ContainerFuelStatus].Response.StatusCode)
+	assert.NotContains(t, report.Errors, ContainerFuelStatus)
+
+	var scopeErr *MissingScopeError
+	assert.True(t, errors.As(report.Errors[ContainerPayAsYouDrive], &scopeErr))
+	assert.Len(t, report.Errors, 4)
+}
+
+func TestClient_GetVehicleReport_vehicleNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	ctx := context.Background()
+	opts := &Options{VehicleID: "EXVETESTVIN000009"}
+
+	report, err := c.GetVehicleReport(ctx, opts)
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, len(Containers()))
+	for ct, result := range report.Results {
+		assert.Equal(t, ReadVehicleNotFound, result.Status, ct)
+		assert.ErrorIs(t, result.Err, ErrVehicleNotFound)
+	}
+
+	// A single container may just not be offered by the vehicle.
+	report, err = c.GetVehicleReport(ctx, opts, ContainerElectricVehicleStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, ReadNotAvailable, report.Results[ContainerElectricVehicleStatus].Status)
+}
+
+func TestClient_GetVehicleReport_containers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"doorlockstatusvehicle":{"value":"2","timestamp":1}}]`))
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	report, err := c.GetVehicleReport(ctx, &Options{VehicleID: fakeVehicleID}, ContainerVehicleLockStatus)
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 1)
+	assert.Nil(t, report.Errors)
+	assert.NotNil(t, report.VehicleLockStatus)
+
+	var validationErr *ValidationError
+	_, err = c.GetVehicleReport(ctx, &Options{VehicleID: fakeVehicleID}, "tires")
+	assert.True(t, errors.As(err, &validationErr))
+	_, err = c.GetVehicleReport(ctx, &Options{})
+	assert.True(t, errors.As(err, &validationErr))
+}
@@ -1,5 +1,17 @@
 package merche
 
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoValue is returned when parsing a resource that holds no value.
+var ErrNoValue = errors.New("resource has no value")
+
 // ResourceMetaInfo struct for ResourceMetaInfo.
 type ResourceMetaInfo struct {
 	Href    *string `json:"href,omitempty"`
@@ -11,4 +23,138 @@ type ResourceMetaInfo struct {
 type Resource struct {
 	Timestamp *int64  `json:"timestamp,omitempty"`
 	Value     *string `json:"value,omitempty"`
+
+	// Name is the name of the resource. It is only set for resources
+	// fetched individually with ResourcesService and for the resources of
+	// the changes sent by Watch.
+	Name string `json:"-"`
+}
+
+// ResourceValueError is returned when the value of a resource cannot be
+// parsed as the requested type.
+type ResourceValueError struct {
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *ResourceValueError) Error() string {
+	return fmt.Sprintf("cannot parse resource value %q as %v: %v", e.Value, e.Type, e.Err)
+}
+
+func (e *ResourceValueError) Unwrap() error { return e.Err }
+
+// Bool parses the value of the resource as a boolean, like "true".
+func (r *Resource) Bool() (bool, error) {
+	v, err := r.value()
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, &ResourceValueError{Value: v, Type: "bool", Err: unwrapNumError(err)}
+	}
+	return b, nil
+}
+
+// Int parses the value of the resource as an integer, like "695438".
+func (r *Resource) Int() (int, error) {
+	v, err := r.value()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &ResourceValueError{Value: v, Type: "int", Err: unwrapNumError(err)}
+	}
+	return i, nil
+}
+
+// Float parses the value of the resource as a floating point number.
+func (r *Resource) Float() (float64, error) {
+	v, err := r.value()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, &ResourceValueError{Value: v, Type: "float64", Err: unwrapNumError(err)}
+	}
+	return f, nil
+}
+
+// Time returns the readout timestamp of the resource.
+func (r *Resource) Time() (time.Time, error) {
+	if r == nil || r.Timestamp == nil {
+		return time.Time{}, errors.New("resource has no timestamp")
+	}
+	return time.UnixMilli(*r.Timestamp), nil
+}
+
+func (r *Resource) value() (string, error) {
+	if r == nil || r.Value == nil {
+		return "", ErrNoValue
+	}
+	return *r.Value, nil
+}
+
+// parse parses the value of the resource into v.
+func (r *Resource) parse(v any) error {
+	var err error
+	switch v := v.(type) {
+	case *bool:
+		*v, err = r.Bool()
+	case *int:
+		*v, err = r.Int()
+	case *float64:
+		*v, err = r.Float()
+	case *string:
+		*v, err = r.value()
+	case encoding.TextUnmarshaler:
+		var s string
+		if s, err = r.value(); err != nil {
+			return err
+		}
+		if err = v.UnmarshalText([]byte(s)); err != nil {
+			return &ResourceValueError{Value: s, Type: strings.TrimPrefix(fmt.Sprintf("%T", v), "*"), Err: err}
+		}
+	default:
+		err = fmt.Errorf("unsupported resource value type %T", v)
+	}
+	return err
+}
+
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// TypedResource is a Resource whose value has been parsed as T.
+type TypedResource[T any] struct {
+	Value T
+	// Timestamp is the readout time of the value. It is the zero time if
+	// the resource has no timestamp.
+	Timestamp time.Time
+}
+
+// ParseResource parses r as a TypedResource. T must be bool, int, float64,
+// string or implement encoding.TextUnmarshaler through its pointer.
+// ParseResource returns nil if r is nil, so that resources not available
+// for a vehicle are not reported as errors.
+func ParseResource[T any](r *Resource) (*TypedResource[T], error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	tr := &TypedResource[T]{}
+	if r.Timestamp != nil {
+		tr.Timestamp = time.UnixMilli(*r.Timestamp)
+	}
+	if err := r.parse(&tr.Value); err != nil {
+		return nil, err
+	}
+	return tr, nil
 }
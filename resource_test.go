@@ -0,0 +1,113 @@
+package merche
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResource_typedValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		resource  *Resource
+		wantBool  bool
+		wantInt   int
+		wantFloat float64
+		wantErrs  [3]bool
+	}{
+		{
+			name:     "boolean",
+			resource: &Resource{Value: String("true")},
+			wantBool: true,
+			wantErrs: [3]bool{false, true, true},
+		},
+		{
+			name:      "integer",
+			resource:  &Resource{Value: String("695438")},
+			wantInt:   695438,
+			wantFloat: 695438,
+			wantErrs:  [3]bool{true, false, false},
+		},
+		{
+			name:      "float",
+			resource:  &Resource{Value: String("214.5")},
+			wantFloat: 214.5,
+			wantErrs:  [3]bool{true, true, false},
+		},
+		{
+			name:     "no value",
+			resource: &Resource{},
+			wantErrs: [3]bool{true, true, true},
+		},
+		{
+			name:     "nil resource",
+			wantErrs: [3]bool{true, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.resource.Bool()
+			assert.Equal(t, tt.wantErrs[0], err != nil, "Bool() error = %v", err)
+			assert.Equal(t, tt.wantBool, b)
+
+			i, err := tt.resource.Int()
+			assert.Equal(t, tt.wantErrs[1], err != nil, "Int() error = %v", err)
+			assert.Equal(t, tt.wantInt, i)
+
+			f, err := tt.resource.Float()
+			assert.Equal(t, tt.wantErrs[2], err != nil, "Float() error = %v", err)
+			assert.Equal(t, tt.wantFloat, f)
+		})
+	}
+}
+
+func TestResource_errors(t *testing.T) {
+	_, err := (&Resource{}).Int()
+	assert.ErrorIs(t, err, ErrNoValue)
+
+	_, err = (&Resource{Value: String("35%")}).Int()
+	assert.EqualError(t, err, `cannot parse resource value "35%" as int: invalid syntax`)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+	var valueErr *ResourceValueError
+	assert.True(t, errors.As(err, &valueErr))
+}
+
+func TestResource_Time(t *testing.T) {
+	got, err := (&Resource{Timestamp: Int64(1541750489000)}).Time()
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2018, 11, 9, 8, 1, 29, 0, time.UTC)))
+
+	_, err = (&Resource{}).Time()
+	assert.Error(t, err)
+}
+
+func TestParseResource(t *testing.T) {
+	odo := &Resource{Value: String("695438"), Timestamp: Int64(1541750489000)}
+
+	got, err := ParseResource[int](odo)
+	assert.NoError(t, err)
+	assert.Equal(t, 695438, got.Value)
+	assert.Equal(t, time.UnixMilli(1541750489000), got.Timestamp)
+
+	missing, err := ParseResource[int](nil)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = ParseResource[bool](odo)
+	assert.Error(t, err)
+
+	_, err = ParseResource[complex64](odo)
+	assert.Error(t, err)
+}
+
+func TestPayAsYouDriveStatus_Odometer(t *testing.T) {
+	status := &PayAsYouDriveStatus{Odo: &Resource{Value: String("695438"), Timestamp: Int64(1541750489000)}}
+
+	got, err := status.Odometer()
+	assert.NoError(t, err)
+	assert.Equal(t, 695438, got.Value)
+}
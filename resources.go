@@ -2,12 +2,12 @@ package merche
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
-const apiPathPrefix = "vehicledata/v2/vehicles"
-
 // ResourcesService handles communication with vehicle available resources.
 type ResourcesService service
 
@@ -18,7 +18,10 @@ type ResourcesService service
 // https://developer.mercedes-benz.com/products/vehicle_status/docs#_3_get_all_values_of_the_vehicle_status_api
 // https://developer.mercedes-benz.com/products/fuel_status/docs#_1_get_the_available_resources_that_can_be_read_out
 func (s *ResourcesService) GetAvailableResources(ctx context.Context, opts *Options) ([]*ResourceMetaInfo, *Response, error) {
-	path := fmt.Sprintf("%v/%v/resources", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "resources")
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -33,3 +36,53 @@ func (s *ResourcesService) GetAvailableResources(ctx context.Context, opts *Opti
 
 	return resources, resp, nil
 }
+
+// GetResource gets the value of a single resource of a vehicle, like "odo".
+// Reading a single resource instead of a whole container lowers the quota
+// used.
+//
+// Mercedes API docs: https://developer.mercedes-benz.com/products/pay_as_you_drive_insurance/docs
+func (s *ResourcesService) GetResource(ctx context.Context, opts *Options, name string) (*Resource, *Response, error) {
+	if name == "" {
+		return nil, nil, &ValidationError{Field: "name", Err: errors.New("resource name must not be empty")}
+	}
+
+	path, err := s.client.vehiclePath(opts, "resources", url.PathEscape(name))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resources map[string]*Resource
+	resp, err := s.client.Do(req, &resources)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	resource, ok := resources[name]
+	if !ok || resource == nil {
+		return nil, resp, fmt.Errorf("resource %q missing in response", name)
+	}
+	resource.Name = name
+
+	return resource, resp, nil
+}
+
+// FollowResource gets the value of the resource described by meta, as
+// returned by GetAvailableResources.
+func (s *ResourcesService) FollowResource(ctx context.Context, meta *ResourceMetaInfo) (*Resource, *Response, error) {
+	if meta == nil || meta.Href == nil {
+		return nil, nil, errors.New("resource meta info has no href")
+	}
+
+	t := parseTarget(*meta.Href)
+	if t.vehicleID == "" || t.resource == "" {
+		return nil, nil, fmt.Errorf("invalid resource href %q", *meta.Href)
+	}
+
+	return s.GetResource(ctx, &Options{VehicleID: t.vehicleID}, t.resource)
+}
@@ -164,3 +164,107 @@ func TestVehicleStatusService_GetAvailableResources(t *testing.T) {
 		})
 	}
 }
+
+func TestResourcesService_GetResource(t *testing.T) {
+	ctx := context.Background()
+
+	type fields struct {
+		mercedesAPIMock *httptest.Server
+	}
+	type args struct {
+		ctx  context.Context
+		opts *Options
+		name string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *Resource
+		wantErr bool
+	}{
+		{
+			name: "nil context error",
+			fields: fields{
+				mercedesAPIMock: createFakeServer(http.StatusOK, ""),
+			},
+			args: args{
+				ctx:  nil,
+				opts: &Options{VehicleID: fakeVehicleID},
+				name: "odo",
+			},
+			wantErr: true,
+		},
+		{
+			name: "resource missing in response",
+			fields: fields{
+				mercedesAPIMock: createFakeServer(http.StatusOK, "pay_as_you_drive_get_odo_status.json"),
+			},
+			args: args{
+				ctx:  ctx,
+				opts: &Options{VehicleID: fakeVehicleID},
+				name: "soc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "get resource",
+			fields: fields{
+				mercedesAPIMock: createFakeServer(http.StatusOK, "pay_as_you_drive_get_odo_status.json"),
+			},
+			args: args{
+				ctx:  ctx,
+				opts: &Options{VehicleID: fakeVehicleID},
+				name: "odo",
+			},
+			want: &Resource{
+				Name:      "odo",
+				Value:     String("695438"),
+				Timestamp: Int64(1541750489000),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, _ := url.Parse(tt.fields.mercedesAPIMock.URL + "/")
+
+			c := NewClient(tt.fields.mercedesAPIMock.Client())
+			c.BaseURL = baseURL
+
+			got, _, err := c.Resources.GetResource(tt.args.ctx, tt.args.opts, tt.args.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resources.GetResource() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equalf(t, tt.want, got, "Resources.GetResource() got = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestResourcesService_FollowResource(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		http.ServeFile(w, r, "testdata/pay_as_you_drive_get_odo_status.json")
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/")
+
+	c := NewClient(server.Client())
+	c.BaseURL = baseURL
+
+	got, _, err := c.Resources.FollowResource(context.Background(), &ResourceMetaInfo{
+		Name: String("odo"),
+		Href: String("/vehicles/EXVETESTVIN000001/resources/odo"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "odo", got.Name)
+	assert.Equal(t, "/vehicledata/v2/vehicles/EXVETESTVIN000001/resources/odo", path)
+
+	_, _, err = c.Resources.FollowResource(context.Background(), &ResourceMetaInfo{Href: String("/vehicles")})
+	assert.Error(t, err)
+
+	_, _, err = c.Resources.FollowResource(context.Background(), &ResourceMetaInfo{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseBackoff = 500 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+)
+
+// RetryPolicy defines how the Client retries failed requests.
+//
+// A request is retried when the Mercedes API answers with one of the
+// RetryableStatusCodes or when sending the request fails with an error
+// accepted by RetryableError. Only idempotent requests are retried; any
+// request can opt out using WithoutRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a request,
+	// including the first one. Values lower than 1 are treated as 1.
+	MaxAttempts int
+
+	// BaseBackoff is the wait time before the first retry. It doubles on
+	// every further retry.
+	BaseBackoff time.Duration
+
+	// MaxBackoff caps the wait time between two attempts. When the
+	// Retry-After header of a response asks to wait longer, the request is
+	// not retried and the error of the last attempt, like a
+	// *RateLimitError, is returned.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, between 0 and 1, of the backoff that is
+	// randomized to avoid synchronized retries.
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP status codes that trigger a retry.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether an error returned while sending the
+	// request triggers a retry. If nil, network errors are retried.
+	RetryableError func(err error) bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy that retries transient
+// Mercedes API failures up to three times with exponential backoff.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseBackoff: defaultBaseBackoff,
+		MaxBackoff:  defaultMaxBackoff,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type retryContextKey struct{}
+
+// WithoutRetry returns a copy of ctx that disables retries for the requests
+// created with it. Non idempotent calls should use it.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(retryContextKey{}).(bool)
+	return disabled
+}
+
+func (p *RetryPolicy) maxAttempts(req *http.Request) int {
+	if p == nil || p.MaxAttempts < 1 || retryDisabled(req.Context()) || !isIdempotent(req.Method) {
+		return 1
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether a request must be retried given the result
+// of the last attempt.
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return err != nil && p.retryableError(err)
+	}
+	return p.retryableStatus(resp.StatusCode)
+}
+
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	// The http.Client wraps the errors of its Transport in a *url.Error,
+	// which is a net.Error itself: only the wrapped error tells whether the
+	// network failed.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// backoff returns the wait time before the given retry. The Retry-After
+// header of resp, if any, takes precedence over the computed backoff. It
+// reports false if Retry-After exceeds MaxBackoff, in which case the
+// request must not be retried.
+func (p *RetryPolicy) backoff(retry int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d, p.MaxBackoff <= 0 || d <= p.MaxBackoff
+		}
+	}
+
+	d := float64(p.BaseBackoff) * math.Pow(2, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d = d - d*jitter + d*jitter*rand.Float64()
+	}
+	return time.Duration(d), true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, expressed
+// either in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// rewind prepares req to be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
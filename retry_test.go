@@ -0,0 +1,174 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_DoRetry(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		BaseBackoff:          time.Millisecond,
+		MaxBackoff:           5 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+
+	tests := []struct {
+		name         string
+		policy       *RetryPolicy
+		ctx          context.Context
+		statusCodes  []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "no retry policy",
+			ctx:          context.Background(),
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "retry until success",
+			policy:       policy,
+			ctx:          context.Background(),
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			name:         "max attempts reached",
+			policy:       policy,
+			ctx:          context.Background(),
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "non retryable status code",
+			policy:       policy,
+			ctx:          context.Background(),
+			statusCodes:  []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "retry disabled by context",
+			policy:       policy,
+			ctx:          WithoutRetry(context.Background()),
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCodes[n-1])
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			baseURL, _ := url.Parse(server.URL + "/")
+
+			c := NewClient(server.Client())
+			c.BaseURL = baseURL
+			c.RetryPolicy = tt.policy
+
+			req, _ := c.NewRequest(tt.ctx, http.MethodGet, "", http.NoBody)
+			resp, err := c.Do(req, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.wantAttempts, resp.Attempts)
+			assert.Equal(t, int32(tt.wantAttempts), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  time.Second,
+	}
+
+	backoff := func(retry int, resp *http.Response) time.Duration {
+		d, ok := p.backoff(retry, resp)
+		assert.True(t, ok)
+		return d
+	}
+	retryAfter := func(v string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{v}}}
+	}
+
+	assert.Equal(t, 100*time.Millisecond, backoff(1, nil))
+	assert.Equal(t, 400*time.Millisecond, backoff(3, nil))
+	assert.Equal(t, time.Second, backoff(10, nil))
+	assert.Equal(t, time.Second, backoff(1, retryAfter("1")))
+
+	_, ok := p.backoff(1, retryAfter("7"))
+	assert.False(t, ok, "Retry-After beyond MaxBackoff must stop the retries")
+
+	p.MaxBackoff = 0
+	assert.Equal(t, 7*time.Second, backoff(1, retryAfter("7")))
+}
+
+func TestClient_DoRetryAfterBeyondMaxBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()), WithRetryPolicy(DefaultRetryPolicy()))
+	assert.NoError(t, err)
+
+	start := time.Now()
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "", http.NoBody)
+	resp, err := c.Do(req, nil)
+
+	var rateLimitErr *RateLimitError
+	if assert.True(t, errors.As(err, &rateLimitErr)) {
+		assert.Equal(t, time.Hour, rateLimitErr.RetryAfter)
+	}
+	assert.Equal(t, 1, resp.Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "empty"},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "http date", value: "Mon, 01 Aug 2022 10:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{name: "past http date", value: "Mon, 01 Aug 2022 09:00:00 GMT", wantOK: true},
+		{name: "invalid", value: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package merche
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. It is injected to make time dependent
+// components deterministic in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Scheduler decides when the vehicles watched by Client.Watch are polled.
+type Scheduler interface {
+	// Next returns the decision of when to poll a vehicle next.
+	Next(vehicleID string) PollDecision
+	// Observe records a poll of a vehicle, that sent requests requests and
+	// found changes, and returns when to poll it next.
+	Observe(vehicleID string, requests int, changes []ResourceChange) PollDecision
+}
+
+// PollReason explains a PollDecision.
+type PollReason string
+
+// Poll reasons.
+const (
+	PollFirst           PollReason = "first poll"
+	PollActivity        PollReason = "recent activity"
+	PollIdle            PollReason = "no activity"
+	PollBudgetPaced     PollReason = "budget paced"
+	PollBudgetExhausted PollReason = "budget exhausted"
+)
+
+// PollDecision is the decision of when to poll a vehicle next.
+type PollDecision struct {
+	VehicleID string
+	// At is when the vehicle should be polled.
+	At time.Time
+	// Wait is the time left until At when the decision was returned.
+	Wait time.Duration
+	// Interval is the polling interval the decision is based on.
+	Interval time.Duration
+	Reason   PollReason
+
+	// RequestsToday is the number of requests sent today for the vehicle.
+	RequestsToday int
+	// Budget is the daily request budget of the vehicle, or 0 if unlimited.
+	Budget int
+}
+
+// DefaultActivityResources are the resources whose changes reveal that a
+// vehicle is being used.
+var DefaultActivityResources = []string{"odo", "doorlockstatusvehicle", "soc"}
+
+// AdaptiveSchedule is a Scheduler polling vehicles often while they are
+// being used and backing off exponentially while they are parked.
+//
+// A vehicle is polled every MinInterval after a change of any of the
+// ActivityResources, and the interval is multiplied by Multiplier after
+// every poll without activity, up to MaxInterval. With a DailyBudget, the
+// requests left for the day are spread until midnight UTC, and polling
+// stops once they are used up.
+//
+// AdaptiveSchedule is safe for concurrent use. It must not be copied
+// after first use.
+type AdaptiveSchedule struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+
+	// ActivityResources defaults to DefaultActivityResources.
+	ActivityResources []string
+
+	// DailyBudget is the number of requests per vehicle and day. Zero means
+	// unlimited.
+	DailyBudget int
+
+	// Clock defaults to the system clock.
+	Clock Clock
+
+	mu       sync.Mutex
+	vehicles map[string]*vehicleSchedule
+}
+
+type vehicleSchedule struct {
+	decision PollDecision
+	interval time.Duration
+	day      time.Time
+	requests int
+	perPoll  int
+}
+
+// DefaultAdaptiveSchedule returns an AdaptiveSchedule polling every minute
+// while a vehicle is used, and at least every hour otherwise.
+func DefaultAdaptiveSchedule() *AdaptiveSchedule {
+	return &AdaptiveSchedule{
+		MinInterval: time.Minute,
+		MaxInterval: time.Hour,
+		Multiplier:  2,
+	}
+}
+
+// Next implements the Scheduler interface. Vehicles never polled are
+// polled right away, unless the budget of the day is exhausted.
+func (s *AdaptiveSchedule) Next(vehicleID string) PollDecision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	v := s.vehicle(vehicleID, now)
+	if v.decision.At.IsZero() {
+		v.decision = PollDecision{VehicleID: vehicleID, At: now, Reason: PollFirst, Budget: s.DailyBudget}
+	}
+
+	d := v.decision
+	d.RequestsToday = v.requests
+	d.Wait = d.At.Sub(now)
+	if d.Wait < 0 {
+		d.Wait = 0
+	}
+	return d
+}
+
+// Observe implements the Scheduler interface.
+func (s *AdaptiveSchedule) Observe(vehicleID string, requests int, changes []ResourceChange) PollDecision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	v := s.vehicle(vehicleID, now)
+	v.requests += requests
+	if requests > 0 {
+		v.perPoll = requests
+	}
+
+	reason := PollIdle
+	switch {
+	case s.active(changes):
+		v.interval, reason = s.minInterval(), PollActivity
+	case v.interval == 0:
+		v.interval = s.minInterval()
+	default:
+		v.interval = time.Duration(float64(v.interval) * s.multiplier())
+		if limit := s.maxInterval(); v.interval > limit {
+			v.interval = limit
+		}
+	}
+
+	interval := v.interval
+	if s.DailyBudget > 0 {
+		midnight := v.day.Add(24 * time.Hour)
+		polls := (s.DailyBudget - v.requests) / v.perPoll
+		if polls <= 0 {
+			interval, reason = midnight.Sub(now), PollBudgetExhausted
+		} else if paced := midnight.Sub(now) / time.Duration(polls); paced > interval {
+			interval, reason = paced, PollBudgetPaced
+		}
+	}
+
+	v.decision = PollDecision{
+		VehicleID:     vehicleID,
+		At:            now.Add(interval),
+		Wait:          interval,
+		Interval:      interval,
+		Reason:        reason,
+		RequestsToday: v.requests,
+		Budget:        s.DailyBudget,
+	}
+	return v.decision
+}
+
+// vehicle returns the schedule of a vehicle, resetting the request count
+// when the day changed.
+func (s *AdaptiveSchedule) vehicle(vehicleID string, now time.Time) *vehicleSchedule {
+	if s.vehicles == nil {
+		s.vehicles = make(map[string]*vehicleSchedule)
+	}
+	v, ok := s.vehicles[vehicleID]
+	if !ok {
+		v = &vehicleSchedule{perPoll: 1}
+		s.vehicles[vehicleID] = v
+	}
+
+	if day := now.UTC().Truncate(24 * time.Hour); !day.Equal(v.day) {
+		v.day, v.requests = day, 0
+	}
+	return v
+}
+
+func (s *AdaptiveSchedule) active(changes []ResourceChange) bool {
+	resources := s.ActivityResources
+	if resources == nil {
+		resources = DefaultActivityResources
+	}
+	for _, c := range changes {
+		for _, r := range resources {
+			if c.Resource == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *AdaptiveSchedule) now() time.Time {
+	if s.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return s.Clock.Now()
+}
+
+func (s *AdaptiveSchedule) minInterval() time.Duration {
+	if s.MinInterval <= 0 {
+		return time.Minute
+	}
+	return s.MinInterval
+}
+
+func (s *AdaptiveSchedule) maxInterval() time.Duration {
+	if s.MaxInterval < s.minInterval() {
+		return s.minInterval()
+	}
+	return s.MaxInterval
+}
+
+func (s *AdaptiveSchedule) multiplier() float64 {
+	if s.Multiplier <= 1 {
+		return 2
+	}
+	return s.Multiplier
+}
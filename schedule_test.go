@@ -0,0 +1,149 @@
+package merche
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestAdaptiveSchedule(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2022, 8, 1, 8, 0, 0, 0, time.UTC)}
+	s := &AdaptiveSchedule{
+		MinInterval: time.Minute,
+		MaxInterval: 10 * time.Minute,
+		Multiplier:  2,
+		Clock:       clock,
+	}
+
+	d := s.Next(fakeVehicleID)
+	assert.Equal(t, PollFirst, d.Reason)
+	assert.Equal(t, clock.Now(), d.At)
+	assert.Zero(t, d.Wait)
+
+	odo := []ResourceChange{{Resource: "odo"}}
+	fuel := []ResourceChange{{Resource: "tanklevelpercent"}}
+
+	steps := []struct {
+		changes      []ResourceChange
+		wantInterval time.Duration
+		wantReason   PollReason
+	}{
+		{nil, time.Minute, PollIdle},
+		{nil, 2 * time.Minute, PollIdle},
+		{fuel, 4 * time.Minute, PollIdle},
+		{nil, 8 * time.Minute, PollIdle},
+		{nil, 10 * time.Minute, PollIdle},
+		{odo, time.Minute, PollActivity},
+		{nil, 2 * time.Minute, PollIdle},
+	}
+	for i, step := range steps {
+		d := s.Observe(fakeVehicleID, 1, step.changes)
+		assert.Equal(t, step.wantInterval, d.Interval, "step %d", i)
+		assert.Equal(t, step.wantReason, d.Reason, "step %d", i)
+		assert.Equal(t, clock.Now().Add(step.wantInterval), d.At, "step %d", i)
+		assert.Equal(t, i+1, d.RequestsToday, "step %d", i)
+		clock.Advance(d.Interval)
+	}
+
+	clock.Advance(-30 * time.Second)
+	d = s.Next(fakeVehicleID)
+	assert.Equal(t, 30*time.Second, d.Wait)
+	assert.Equal(t, PollIdle, d.Reason)
+}
+
+func TestAdaptiveSchedule_budget(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2022, 8, 1, 23, 0, 0, 0, time.UTC)}
+	s := DefaultAdaptiveSchedule()
+	s.DailyBudget = 10
+	s.Clock = clock
+
+	d := s.Observe(fakeVehicleID, 2, nil)
+	assert.Equal(t, PollBudgetPaced, d.Reason)
+	assert.Equal(t, 15*time.Minute, d.Interval, "4 polls left for the last hour")
+
+	for i := 0; i < 3; i++ {
+		d = s.Observe(fakeVehicleID, 2, []ResourceChange{{Resource: "soc"}})
+	}
+	assert.Equal(t, 8, d.RequestsToday)
+	assert.Equal(t, PollBudgetPaced, d.Reason)
+	assert.Equal(t, time.Hour, d.Interval)
+
+	d = s.Observe(fakeVehicleID, 2, nil)
+	assert.Equal(t, PollBudgetExhausted, d.Reason)
+	assert.Equal(t, time.Date(2022, 8, 2, 0, 0, 0, 0, time.UTC), d.At)
+
+	clock.Advance(time.Hour)
+	d = s.Next(fakeVehicleID)
+	assert.Zero(t, d.RequestsToday, "budget is reset every day")
+	assert.Zero(t, d.Wait)
+
+	d = s.Observe(fakeVehicleID, 2, []ResourceChange{{Resource: "soc"}})
+	assert.Equal(t, PollBudgetPaced, d.Reason)
+	assert.Equal(t, 6*time.Hour, d.Interval, "4 polls left for the day")
+
+	assert.Equal(t, PollFirst, s.Next("EXVETESTVIN000002").Reason, "budgets are per vehicle")
+}
+
+func TestClient_Watch_schedule(t *testing.T) {
+	var mu sync.Mutex
+	odo := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		odo++
+		fmt.Fprintf(w, `[{"odo":{"value":"%d","timestamp":%d}}]`, odo, odo)
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &AdaptiveSchedule{MinInterval: time.Millisecond, MaxInterval: time.Millisecond}
+	events, err := c.Watch(ctx, &WatchOptions{
+		VehicleID:  fakeVehicleID,
+		Containers: []Container{ContainerPayAsYouDrive},
+		Schedule:   s,
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		e := <-events
+		assert.NoError(t, e.Err)
+	}
+	cancel()
+	for range events {
+	}
+
+	d := s.Next(fakeVehicleID)
+	assert.Equal(t, PollActivity, d.Reason)
+	assert.GreaterOrEqual(t, d.RequestsToday, 3)
+}
@@ -0,0 +1,76 @@
+package merche
+
+import "fmt"
+
+// OAuth2 scopes of the Mercedes API.
+const (
+	ScopeVehicleStatus         = "mb:vehicle:mbdata:vehiclestatus"
+	ScopeVehicleLockStatus     = "mb:vehicle:mbdata:vehiclelock"
+	ScopeFuelStatus            = "mb:vehicle:mbdata:fuelstatus"
+	ScopeElectricVehicleStatus = "mb:vehicle:mbdata:evstatus"
+	ScopePayAsYouDrive         = "mb:vehicle:mbdata:payasyoudrive"
+
+	// ScopeOfflineAccess grants a refresh token.
+	ScopeOfflineAccess = "offline_access"
+)
+
+// ScopesFor returns the scopes to request in the authorization flow to
+// read the given containers.
+func ScopesFor(cs ...Container) []string {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, c := range cs {
+		if scope := c.Scope(); scope != "" && !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// MissingScopeError is returned, before sending the request, when a
+// request requires a scope that has not been granted to the token of the
+// Client. See WithGrantedScopes.
+type MissingScopeError struct {
+	Service string
+	Scope   string
+}
+
+func (e *MissingScopeError) Error() string {
+	return fmt.Sprintf("%v requires the scope %q, which has not been granted", e.Service, e.Scope)
+}
+
+// Is reports whether target is ErrForbidden.
+func (e *MissingScopeError) Is(target error) bool { return target == ErrForbidden }
+
+// scopeGranted reports whether scope is known to be granted to the token
+// of the Client.
+func (c *Client) scopeGranted(scope string) bool {
+	return scope != "" && c.grantedScopes[scope]
+}
+
+// checkScopes checks that the scope required by a request path has been
+// granted. It is a no-op unless the granted scopes are known.
+func (c *Client) checkScopes(path string) error {
+	if c.grantedScopes == nil {
+		return nil
+	}
+
+	t := parseTarget(path)
+	cs := resourceContainers(t.resource)
+	if t.container != "" {
+		cs = []Container{t.container}
+	}
+
+	var missing error
+	for _, ct := range cs {
+		scope := ct.Scope()
+		if scope == "" || c.grantedScopes[scope] {
+			return nil
+		}
+		if missing == nil {
+			missing = &MissingScopeError{Service: ct.Service(), Scope: scope}
+		}
+	}
+	return missing
+}
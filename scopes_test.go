@@ -0,0 +1,81 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopesFor(t *testing.T) {
+	assert.Equal(t,
+		[]string{ScopeFuelStatus, ScopeElectricVehicleStatus},
+		ScopesFor(ContainerFuelStatus, ContainerElectricVehicleStatus, ContainerFuelStatus),
+	)
+	assert.Len(t, ScopesFor(Containers()...), 5)
+	assert.Empty(t, ScopesFor(Container("unknown")))
+}
+
+func TestContainer_Resources(t *testing.T) {
+	assert.Equal(t, []string{"rangeliquid", "tanklevelpercent"}, ContainerFuelStatus.Resources())
+	assert.Len(t, ContainerVehicleStatus.Resources(), 16)
+	assert.Equal(t, "PayAsYouDriveService", ContainerPayAsYouDrive.Service())
+
+	assert.Equal(t,
+		[]Container{ContainerVehicleStatus, ContainerVehicleLockStatus},
+		resourceContainers("doorlockstatusdecklid"),
+	)
+}
+
+func TestClient_checkScopes(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.HasSuffix(r.URL.Path, "/resources") {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`{"doorlockstatusdecklid":{"value":"false"}}`))
+	}))
+	defer server.Close()
+
+	c, err := New(
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithGrantedScopes(ScopeVehicleLockStatus, ScopeOfflineAccess),
+	)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	opts := &Options{VehicleID: fakeVehicleID}
+
+	_, _, err = c.FuelStatus.GetFuelStatus(ctx, opts)
+	var scopeErr *MissingScopeError
+	if assert.True(t, errors.As(err, &scopeErr)) {
+		assert.Equal(t, "FuelStatusService", scopeErr.Service)
+		assert.Equal(t, ScopeFuelStatus, scopeErr.Scope)
+	}
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, _, err = c.Resources.GetResource(ctx, opts, "odo")
+	assert.True(t, errors.As(err, &scopeErr))
+
+	_, _, err = c.Resources.GetResource(ctx, opts, "doorlockstatusdecklid")
+	assert.NoError(t, err, "resource of several containers needs one of their scopes")
+
+	_, _, err = c.Resources.GetAvailableResources(ctx, opts)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, requests)
+
+	// No granted scopes, as in a token response without scope, are unknown.
+	c, err = New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()), WithGrantedScopes())
+	assert.NoError(t, err)
+	_, _, err = c.FuelStatus.GetFuelStatus(ctx, opts)
+	assert.False(t, errors.As(err, &scopeErr))
+	assert.Equal(t, 3, requests)
+}
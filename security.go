@@ -0,0 +1,238 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity is the severity of a SecurityFinding.
+type Severity int
+
+// Severities of security findings.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler using the severity name.
+func (s Severity) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// SecurityFinding is an issue found assessing the security of a vehicle,
+// like an open window.
+type SecurityFinding struct {
+	Resource    string   `json:"resource"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	// Timestamp is when the resource was read out of the vehicle.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SecurityReport is the security assessment of a vehicle.
+type SecurityReport struct {
+	VehicleID string `json:"vehicleId,omitempty"`
+	// Secure reports whether there is no finding more severe than
+	// SeverityInfo.
+	Secure bool `json:"secure"`
+	// Severity is the highest severity of the findings.
+	Severity Severity          `json:"severity"`
+	Findings []SecurityFinding `json:"findings"`
+
+	// ReadAt is the timestamp of the oldest resource assessed, and DataAge
+	// its age when the report was generated.
+	ReadAt      time.Time     `json:"readAt"`
+	DataAge     time.Duration `json:"dataAge"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+}
+
+// AssessSecurity assesses the security of a vehicle from its lock status
+// and its vehicle status, as of now. Either status may be nil.
+//
+// Unlocked vehicles and open doors are critical. Open windows, sunroof or
+// rooftop, an unlocked decklid or rooftop, and an unknown lock status are
+// warnings. An unlocked gas lid is informative.
+func AssessSecurity(lock *VehicleLockStatus, status *VehicleStatus, now time.Time) *SecurityReport {
+	if lock == nil {
+		lock = &VehicleLockStatus{}
+	}
+	if status == nil {
+		status = &VehicleStatus{}
+	}
+	a := &assessment{report: &SecurityReport{Secure: true, GeneratedAt: now, Findings: []SecurityFinding{}}}
+
+	if lock.Doorlockstatusvehicle == nil {
+		a.add("doorlockstatusvehicle", "vehicle lock status unknown", SeverityWarning, time.Time{})
+	}
+	assess(a, "doorlockstatusvehicle", lock.LockStatus, SeverityCritical, func(s DoorLockStatus) string {
+		return when(!s.IsLocked(), "vehicle unlocked")
+	})
+
+	doors := []struct {
+		name string
+		get  func() (*TypedResource[bool], error)
+	}{
+		{"front left", status.DoorFrontLeftOpen},
+		{"front right", status.DoorFrontRightOpen},
+		{"rear left", status.DoorRearLeftOpen},
+		{"rear right", status.DoorRearRightOpen},
+	}
+	for _, d := range doors {
+		assess(a, "doorstatus"+compact(d.name), d.get, SeverityCritical, func(open bool) string {
+			return when(open, d.name+" door open")
+		})
+	}
+
+	decklid := lock.DecklidUnlocked
+	if lock.Doorlockstatusdecklid == nil {
+		decklid = status.DecklidUnlocked
+	}
+	assess(a, "doorlockstatusdecklid", decklid, SeverityWarning, func(unlocked bool) string {
+		return when(unlocked, "decklid unlocked")
+	})
+
+	windows := []struct {
+		name string
+		get  func() (*TypedResource[WindowStatus], error)
+	}{
+		{"front left", status.WindowFrontLeft},
+		{"front right", status.WindowFrontRight},
+		{"rear left", status.WindowRearLeft},
+		{"rear right", status.WindowRearRight},
+	}
+	for _, w := range windows {
+		assess(a, "windowstatus"+compact(w.name), w.get, SeverityWarning, func(s WindowStatus) string {
+			return when(s.IsOpen(), w.name+" window open")
+		})
+	}
+
+	assess(a, "sunroofstatus", status.Sunroof, SeverityWarning, func(s SunroofStatus) string {
+		return when(s.IsOpen(), "sunroof open")
+	})
+	assess(a, "rooftopstatus", status.Rooftop, SeverityWarning, func(s RooftopStatus) string {
+		if s.IsOpen() {
+			return "rooftop open"
+		}
+		return when(!s.IsLocked(), "rooftop unlocked")
+	})
+	assess(a, "doorlockstatusgas", lock.GasLidUnlocked, SeverityInfo, func(unlocked bool) string {
+		return when(unlocked, "gas lid unlocked")
+	})
+
+	if !a.report.ReadAt.IsZero() {
+		a.report.DataAge = now.Sub(a.report.ReadAt)
+	}
+	return a.report
+}
+
+type assessment struct {
+	report *SecurityReport
+}
+
+func (a *assessment) add(resource, description string, severity Severity, timestamp time.Time) {
+	a.report.Findings = append(a.report.Findings, SecurityFinding{
+		Resource:    resource,
+		Description: description,
+		Severity:    severity,
+		Timestamp:   timestamp,
+	})
+	if severity > a.report.Severity {
+		a.report.Severity = severity
+	}
+	if severity > SeverityInfo {
+		a.report.Secure = false
+	}
+}
+
+// read records the timestamp of a resource assessed.
+func (a *assessment) read(timestamp time.Time) {
+	if timestamp.IsZero() {
+		return
+	}
+	if a.report.ReadAt.IsZero() || timestamp.Before(a.report.ReadAt) {
+		a.report.ReadAt = timestamp
+	}
+}
+
+// assess adds a finding with the description returned by finding for the
+// value of the resource returned by get, unless it is empty. Invalid values
+// are reported as warnings. Missing resources are skipped.
+func assess[T any](a *assessment, resource string, get func() (*TypedResource[T], error), severity Severity, finding func(T) string) {
+	r, err := get()
+	if err != nil {
+		a.add(resource, resource+" has an invalid value", SeverityWarning, time.Time{})
+		return
+	}
+	if r == nil {
+		return
+	}
+	a.read(r.Timestamp)
+
+	if v, ok := any(r.Value).(interface{ IsValid() bool }); ok && !v.IsValid() {
+		a.add(resource, resource+" has an unknown code", SeverityWarning, r.Timestamp)
+		return
+	}
+	if description := finding(r.Value); description != "" {
+		a.add(resource, description, severity, r.Timestamp)
+	}
+}
+
+// when returns description if insecure, and "" otherwise.
+func when(insecure bool, description string) string {
+	if insecure {
+		return description
+	}
+	return ""
+}
+
+// compact returns a position like "front left" as used in resource names.
+func compact(position string) string {
+	return strings.ReplaceAll(position, " ", "")
+}
+
+// SecurityService assesses the security of vehicles out of the Vehicle Lock
+// Status and Vehicle Status APIs.
+type SecurityService service
+
+// GetSecurityReport gets the lock status and the vehicle status of a
+// vehicle and assesses its security with AssessSecurity. A container
+// without data available is assessed as missing. The Response is the one
+// of the last request sent.
+func (s *SecurityService) GetSecurityReport(ctx context.Context, opts *Options) (*SecurityReport, *Response, error) {
+	lock, resp, err := s.client.VehicleLockStatus.GetVehicleLockStatusSnapshot(ctx, opts)
+	if err != nil && !errors.Is(err, ErrNoDataAvailable) {
+		return nil, resp, err
+	}
+
+	status, resp, err := s.client.VehicleStatus.GetVehicleStatusSnapshot(ctx, opts)
+	if err != nil && !errors.Is(err, ErrNoDataAvailable) {
+		return nil, resp, err
+	}
+
+	var l *VehicleLockStatus
+	if lock != nil {
+		l = lock.Status
+	}
+	var v *VehicleStatus
+	if status != nil {
+		v = status.Status
+	}
+
+	report := AssessSecurity(l, v, time.Now())
+	report.VehicleID = opts.VehicleID
+	return report, resp, nil
+}
@@ -0,0 +1,123 @@
+package merche
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssessSecurity(t *testing.T) {
+	now := time.UnixMilli(10000)
+
+	tests := []struct {
+		name         string
+		lock         *VehicleLockStatus
+		status       *VehicleStatus
+		wantSecure   bool
+		wantSeverity Severity
+		wantFindings []string
+	}{
+		{
+			name: "secure",
+			lock: &VehicleLockStatus{
+				Doorlockstatusvehicle: resource("2", 4000),
+				Doorlockstatusdecklid: resource("false", 4000),
+				Doorlockstatusgas:     resource("true", 5000),
+			},
+			status: &VehicleStatus{
+				Doorstatusfrontleft:   resource("false", 6000),
+				Windowstatusfrontleft: resource("2", 6000),
+				Rooftopstatus:         resource("2", 6000),
+			},
+			wantSecure:   true,
+			wantSeverity: SeverityInfo,
+			wantFindings: []string{"gas lid unlocked"},
+		},
+		{
+			name: "unlocked with open door",
+			lock: &VehicleLockStatus{Doorlockstatusvehicle: resource("0", 4000)},
+			status: &VehicleStatus{
+				Doorstatusrearright:   resource("true", 6000),
+				Doorlockstatusdecklid: resource("true", 6000),
+				Windowstatusrearleft:  resource("4", 6000),
+			},
+			wantSeverity: SeverityCritical,
+			wantFindings: []string{"vehicle unlocked", "rear right door open", "decklid unlocked", "rear left window open"},
+		},
+		{
+			name: "open roofs",
+			lock: &VehicleLockStatus{Doorlockstatusvehicle: resource("1", 4000)},
+			status: &VehicleStatus{
+				Sunroofstatus: resource("1", 6000),
+				Rooftopstatus: resource("0", 6000),
+			},
+			wantSeverity: SeverityWarning,
+			wantFindings: []string{"sunroof open", "rooftop unlocked"},
+		},
+		{
+			name:         "missing data",
+			wantSeverity: SeverityWarning,
+			wantFindings: []string{"vehicle lock status unknown"},
+		},
+		{
+			name: "invalid values",
+			lock: &VehicleLockStatus{Doorlockstatusvehicle: resource("9", 4000)},
+			status: &VehicleStatus{
+				Doorstatusfrontleft: resource("ajar", 6000),
+			},
+			wantSeverity: SeverityWarning,
+			wantFindings: []string{"doorlockstatusvehicle has an unknown code", "doorstatusfrontleft has an invalid value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AssessSecurity(tt.lock, tt.status, now)
+
+			assert.Equal(t, tt.wantSecure, report.Secure)
+			assert.Equal(t, tt.wantSeverity, report.Severity)
+
+			var findings []string
+			for _, f := range report.Findings {
+				findings = append(findings, f.Description)
+			}
+			assert.Equal(t, tt.wantFindings, findings)
+		})
+	}
+
+	report := AssessSecurity(&VehicleLockStatus{Doorlockstatusvehicle: resource("2", 4000)}, &VehicleStatus{
+		Doorstatusfrontleft: resource("false", 6000),
+	}, now)
+	assert.True(t, report.ReadAt.Equal(time.UnixMilli(4000)))
+	assert.Equal(t, 6*time.Second, report.DataAge)
+}
+
+func TestSecurityService_GetSecurityReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/vehiclelockstatus"):
+			w.Write([]byte(`[{"doorlockstatusvehicle":{"value":"2","timestamp":1}},{"doorlockstatusdecklid":{"value":"true","timestamp":1}}]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+
+	report, _, err := c.Security.GetSecurityReport(context.Background(), &Options{VehicleID: fakeVehicleID})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeVehicleID, report.VehicleID)
+	assert.False(t, report.Secure)
+	if assert.Len(t, report.Findings, 1) {
+		assert.Equal(t, "doorlockstatusdecklid", report.Findings[0].Resource)
+	}
+
+	_, _, err = c.Security.GetSecurityReport(context.Background(), &Options{})
+	assert.Error(t, err)
+}
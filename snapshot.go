@@ -0,0 +1,62 @@
+package merche
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Snapshot is a container response folded into a single value.
+//
+// The Mercedes API returns a container as an array of objects holding a
+// single resource each. Status merges all of them, while Raw keeps the
+// array as it was returned.
+type Snapshot[T any] struct {
+	Status *T
+	Raw    []*T
+}
+
+// DuplicateResourceError is returned when a container response holds the
+// same resource more than once.
+type DuplicateResourceError struct {
+	Resource string
+}
+
+func (e *DuplicateResourceError) Error() string {
+	return fmt.Sprintf("duplicate resource %q in container response", e.Resource)
+}
+
+// newSnapshot merges the resources of raw into a single value. T must be a
+// struct whose fields are pointers.
+func newSnapshot[T any](raw []*T) (*Snapshot[T], error) {
+	status := new(T)
+	dst := reflect.ValueOf(status).Elem()
+
+	for _, item := range raw {
+		if item == nil {
+			continue
+		}
+		src := reflect.ValueOf(item).Elem()
+		for i := 0; i < src.NumField(); i++ {
+			f := src.Field(i)
+			if f.Kind() != reflect.Ptr || f.IsNil() {
+				continue
+			}
+			if !dst.Field(i).IsNil() {
+				return nil, &DuplicateResourceError{Resource: resourceName(src.Type().Field(i))}
+			}
+			dst.Field(i).Set(f)
+		}
+	}
+
+	return &Snapshot[T]{Status: status, Raw: raw}, nil
+}
+
+// resourceName returns the Mercedes API name of the resource held by f.
+func resourceName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
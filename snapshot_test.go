@@ -0,0 +1,103 @@
+package merche
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newSnapshot(t *testing.T) {
+	soc := &Resource{Value: String("35"), Timestamp: Int64(1541749824000)}
+	rangeElectric := &Resource{Value: String("1021"), Timestamp: Int64(1541749824000)}
+
+	tests := []struct {
+		name    string
+		raw     []*ElectricVehicleStatus
+		want    *ElectricVehicleStatus
+		wantErr error
+	}{
+		{
+			name: "empty container",
+			want: &ElectricVehicleStatus{},
+		},
+		{
+			name: "merge resources",
+			raw:  []*ElectricVehicleStatus{{Soc: soc}, nil, {RangeElectric: rangeElectric}},
+			want: &ElectricVehicleStatus{Soc: soc, RangeElectric: rangeElectric},
+		},
+		{
+			name:    "duplicate resource",
+			raw:     []*ElectricVehicleStatus{{Soc: soc}, {Soc: soc}},
+			wantErr: &DuplicateResourceError{Resource: "soc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newSnapshot(tt.raw)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Status)
+			assert.Equal(t, tt.raw, got.Raw)
+		})
+	}
+}
+
+func TestVehicleLockStatusService_GetVehicleLockStatusSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	type fields struct {
+		mercedesAPIMock *httptest.Server
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *VehicleLockStatus
+		wantErr bool
+	}{
+		{
+			name: "api error",
+			fields: fields{
+				mercedesAPIMock: createFakeServer(http.StatusBadRequest, "exve_error.json"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "get lock status snapshot",
+			fields: fields{
+				mercedesAPIMock: createFakeServer(http.StatusOK, "vehicle_lock_status_get_containers.json"),
+			},
+			want: &VehicleLockStatus{
+				Doorlockstatusvehicle: &Resource{Value: String("1"), Timestamp: Int64(1541749824000)},
+				Doorlockstatusdecklid: &Resource{Value: String("true"), Timestamp: Int64(1541749824000)},
+				Doorlockstatusgas:     &Resource{Value: String("true"), Timestamp: Int64(1541749824000)},
+				PositionHeading:       &Resource{Value: String("214"), Timestamp: Int64(1541749824000)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, _ := url.Parse(tt.fields.mercedesAPIMock.URL + "/")
+
+			c := NewClient(tt.fields.mercedesAPIMock.Client())
+			c.BaseURL = baseURL
+
+			got, _, err := c.VehicleLockStatus.GetVehicleLockStatusSnapshot(ctx, &Options{VehicleID: fakeVehicleID})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VehicleLockStatus.GetVehicleLockStatusSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got.Status)
+			assert.Len(t, got.Raw, 4)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package merche
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// WindowStatus is the status of a window, as reported by the
+// windowstatus* resources.
+type WindowStatus int
+
+// Window status codes.
+const (
+	WindowIntermediate WindowStatus = iota
+	WindowOpen
+	WindowClosed
+	WindowAiringPosition
+	WindowIntermediateAiringPosition
+	WindowRunning
+)
+
+var windowStatusNames = []string{
+	"intermediate",
+	"open",
+	"closed",
+	"airing position",
+	"intermediate airing position",
+	"running",
+}
+
+func (s WindowStatus) String() string { return codeName("WindowStatus", int(s), windowStatusNames) }
+
+// IsValid reports whether s is a documented window status code.
+func (s WindowStatus) IsValid() bool { return validCode(int(s), windowStatusNames) }
+
+// IsOpen reports whether the window is not fully closed.
+func (s WindowStatus) IsOpen() bool { return s.IsValid() && s != WindowClosed }
+
+// MarshalText implements encoding.TextMarshaler using the API code.
+func (s WindowStatus) MarshalText() ([]byte, error) { return marshalCode(int(s)) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unknown codes are
+// accepted and can be detected with IsValid.
+func (s *WindowStatus) UnmarshalText(text []byte) error { return unmarshalCode((*int)(s), text) }
+
+// SunroofStatus is the status of the sunroof, as reported by the
+// sunroofstatus resource.
+type SunroofStatus int
+
+// Sunroof status codes.
+const (
+	SunroofClosed SunroofStatus = iota
+	SunroofOpen
+	SunroofLiftingOpen
+	SunroofRunning
+	SunroofAntiBoomingPosition
+	SunroofSlidingIntermediate
+	SunroofLiftingIntermediate
+)
+
+var sunroofStatusNames = []string{
+	"closed",
+	"open",
+	"lifting open",
+	"running",
+	"anti-booming position",
+	"sliding intermediate",
+	"lifting intermediate",
+}
+
+func (s SunroofStatus) String() string { return codeName("SunroofStatus", int(s), sunroofStatusNames) }
+
+// IsValid reports whether s is a documented sunroof status code.
+func (s SunroofStatus) IsValid() bool { return validCode(int(s), sunroofStatusNames) }
+
+// IsOpen reports whether the sunroof is not fully closed.
+func (s SunroofStatus) IsOpen() bool { return s.IsValid() && s != SunroofClosed }
+
+// MarshalText implements encoding.TextMarshaler using the API code.
+func (s SunroofStatus) MarshalText() ([]byte, error) { return marshalCode(int(s)) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unknown codes are
+// accepted and can be detected with IsValid.
+func (s *SunroofStatus) UnmarshalText(text []byte) error { return unmarshalCode((*int)(s), text) }
+
+// RooftopStatus is the status of the convertible top, as reported by the
+// rooftopstatus resource.
+type RooftopStatus int
+
+// Rooftop status codes.
+const (
+	RooftopUnlocked RooftopStatus = iota
+	RooftopOpenAndLocked
+	RooftopClosedAndLocked
+)
+
+var rooftopStatusNames = []string{
+	"unlocked",
+	"open and locked",
+	"closed and locked",
+}
+
+func (s RooftopStatus) String() string { return codeName("RooftopStatus", int(s), rooftopStatusNames) }
+
+// IsValid reports whether s is a documented rooftop status code.
+func (s RooftopStatus) IsValid() bool { return validCode(int(s), rooftopStatusNames) }
+
+// IsOpen reports whether the rooftop is open.
+func (s RooftopStatus) IsOpen() bool { return s == RooftopOpenAndLocked }
+
+// IsLocked reports whether the rooftop is locked.
+func (s RooftopStatus) IsLocked() bool {
+	return s == RooftopOpenAndLocked || s == RooftopClosedAndLocked
+}
+
+// MarshalText implements encoding.TextMarshaler using the API code.
+func (s RooftopStatus) MarshalText() ([]byte, error) { return marshalCode(int(s)) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unknown codes are
+// accepted and can be detected with IsValid.
+func (s *RooftopStatus) UnmarshalText(text []byte) error { return unmarshalCode((*int)(s), text) }
+
+// LightSwitchPosition is the position of the light switch, as reported by
+// the lightswitchposition resource.
+type LightSwitchPosition int
+
+// Light switch positions.
+const (
+	LightSwitchAuto LightSwitchPosition = iota
+	LightSwitchHeadlights
+	LightSwitchSidelightLeft
+	LightSwitchSidelightRight
+	LightSwitchParkingLight
+)
+
+var lightSwitchPositionNames = []string{
+	"auto",
+	"headlights",
+	"sidelight left",
+	"sidelight right",
+	"parking light",
+}
+
+func (p LightSwitchPosition) String() string {
+	return codeName("LightSwitchPosition", int(p), lightSwitchPositionNames)
+}
+
+// IsValid reports whether p is a documented light switch position.
+func (p LightSwitchPosition) IsValid() bool { return validCode(int(p), lightSwitchPositionNames) }
+
+// MarshalText implements encoding.TextMarshaler using the API code.
+func (p LightSwitchPosition) MarshalText() ([]byte, error) { return marshalCode(int(p)) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unknown codes are
+// accepted and can be detected with IsValid.
+func (p *LightSwitchPosition) UnmarshalText(text []byte) error {
+	return unmarshalCode((*int)(p), text)
+}
+
+// DoorLockStatus is the lock status of the vehicle, as reported by the
+// doorlockstatusvehicle resource.
+type DoorLockStatus int
+
+// Vehicle lock status codes.
+const (
+	DoorLockUnlocked DoorLockStatus = iota
+	DoorLockInternalLocked
+	DoorLockExternalLocked
+	DoorLockSelectiveUnlocked
+)
+
+var doorLockStatusNames = []string{
+	"unlocked",
+	"internal locked",
+	"external locked",
+	"selective unlocked",
+}
+
+func (s DoorLockStatus) String() string {
+	return codeName("DoorLockStatus", int(s), doorLockStatusNames)
+}
+
+// IsValid reports whether s is a documented lock status code.
+func (s DoorLockStatus) IsValid() bool { return validCode(int(s), doorLockStatusNames) }
+
+// IsLocked reports whether the vehicle is locked, either from the inside
+// or from the outside.
+func (s DoorLockStatus) IsLocked() bool {
+	return s == DoorLockInternalLocked || s == DoorLockExternalLocked
+}
+
+// MarshalText implements encoding.TextMarshaler using the API code.
+func (s DoorLockStatus) MarshalText() ([]byte, error) { return marshalCode(int(s)) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unknown codes are
+// accepted and can be detected with IsValid.
+func (s *DoorLockStatus) UnmarshalText(text []byte) error { return unmarshalCode((*int)(s), text) }
+
+func codeName(typ string, code int, names []string) string {
+	if validCode(code, names) {
+		return names[code]
+	}
+	return fmt.Sprintf("%v(%d)", typ, code)
+}
+
+func validCode(code int, names []string) bool {
+	return code >= 0 && code < len(names)
+}
+
+func marshalCode(code int) ([]byte, error) {
+	return []byte(strconv.Itoa(code)), nil
+}
+
+func unmarshalCode(code *int, text []byte) error {
+	c, err := strconv.Atoi(string(text))
+	if err != nil {
+		return unwrapNumError(err)
+	}
+	*code = c
+	return nil
+}
@@ -0,0 +1,86 @@
+package merche
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowStatus(t *testing.T) {
+	tests := []struct {
+		code      string
+		want      WindowStatus
+		wantName  string
+		wantValid bool
+		wantOpen  bool
+	}{
+		{code: "0", want: WindowIntermediate, wantName: "intermediate", wantValid: true, wantOpen: true},
+		{code: "2", want: WindowClosed, wantName: "closed", wantValid: true},
+		{code: "5", want: WindowRunning, wantName: "running", wantValid: true, wantOpen: true},
+		{code: "9", want: WindowStatus(9), wantName: "WindowStatus(9)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			var got WindowStatus
+			assert.NoError(t, got.UnmarshalText([]byte(tt.code)))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantName, got.String())
+			assert.Equal(t, tt.wantValid, got.IsValid())
+			assert.Equal(t, tt.wantOpen, got.IsOpen())
+
+			text, _ := got.MarshalText()
+			assert.Equal(t, tt.code, string(text))
+		})
+	}
+
+	var s WindowStatus
+	assert.Error(t, s.UnmarshalText([]byte("open")))
+}
+
+func TestStatusCodes_helpers(t *testing.T) {
+	assert.True(t, SunroofLiftingOpen.IsOpen())
+	assert.False(t, SunroofClosed.IsOpen())
+
+	assert.True(t, RooftopOpenAndLocked.IsOpen())
+	assert.True(t, RooftopClosedAndLocked.IsLocked())
+	assert.False(t, RooftopUnlocked.IsLocked())
+
+	assert.True(t, DoorLockExternalLocked.IsLocked())
+	assert.False(t, DoorLockSelectiveUnlocked.IsLocked())
+	assert.False(t, DoorLockStatus(7).IsValid())
+
+	assert.Equal(t, "sidelight left", LightSwitchSidelightLeft.String())
+}
+
+func TestStatusCodes_JSON(t *testing.T) {
+	var got map[string]DoorLockStatus
+	assert.NoError(t, json.Unmarshal([]byte(`{"vehicle":"2"}`), &got))
+	assert.Equal(t, DoorLockExternalLocked, got["vehicle"])
+
+	data, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"vehicle":"2"}`, string(data))
+}
+
+func TestVehicleStatus_typedCodes(t *testing.T) {
+	status := &VehicleStatus{
+		Windowstatusrearleft: &Resource{Value: String("5"), Timestamp: Int64(1541751073000)},
+		Sunroofstatus:        &Resource{Value: String("x")},
+	}
+
+	window, err := status.WindowRearLeft()
+	assert.NoError(t, err)
+	assert.Equal(t, WindowRunning, window.Value)
+
+	_, err = status.Sunroof()
+	assert.EqualError(t, err, `cannot parse resource value "x" as merche.SunroofStatus: invalid syntax`)
+
+	rooftop, err := status.Rooftop()
+	assert.NoError(t, err)
+	assert.Nil(t, rooftop)
+
+	lock, err := (&VehicleLockStatus{Doorlockstatusvehicle: &Resource{Value: String("1")}}).LockStatus()
+	assert.NoError(t, err)
+	assert.True(t, lock.Value.IsLocked())
+}
@@ -2,7 +2,6 @@ package merche
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 )
 
@@ -14,6 +13,26 @@ type VehicleLockStatus struct {
 	PositionHeading       *Resource `json:"positionHeading,omitempty"`
 }
 
+// LockStatus returns the lock status of the vehicle.
+func (s *VehicleLockStatus) LockStatus() (*TypedResource[DoorLockStatus], error) {
+	return ParseResource[DoorLockStatus](s.Doorlockstatusvehicle)
+}
+
+// DecklidUnlocked reports whether the deck lid is unlocked.
+func (s *VehicleLockStatus) DecklidUnlocked() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorlockstatusdecklid)
+}
+
+// GasLidUnlocked reports whether the gas lid is unlocked.
+func (s *VehicleLockStatus) GasLidUnlocked() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorlockstatusgas)
+}
+
+// Heading returns the heading of the vehicle in degrees.
+func (s *VehicleLockStatus) Heading() (*TypedResource[float64], error) {
+	return ParseResource[float64](s.PositionHeading)
+}
+
 // VehicleLockStatusService handles communication with vehicle lock status related
 // methods of the Mercedes API.
 //
@@ -27,7 +46,10 @@ type VehicleLockStatusService service
 //
 // Mercedes API docs: https://developer.mercedes-benz.com/products/vehicle_lock_status/docs#_3_get_all_values_of_the_vehicle_lock_status_api
 func (s *VehicleLockStatusService) GetVehicleLockStatus(ctx context.Context, opts *Options) ([]*VehicleLockStatus, *Response, error) {
-	path := fmt.Sprintf("%v/%v/containers/vehiclelockstatus", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "containers", string(ContainerVehicleLockStatus))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -42,3 +64,21 @@ func (s *VehicleLockStatusService) GetVehicleLockStatus(ctx context.Context, opt
 
 	return status, resp, nil
 }
+
+// GetVehicleLockStatusSnapshot gets the vehicle lock status of a vehicle
+// like GetVehicleLockStatus, folding all the resources of the container into
+// a single VehicleLockStatus. A *DuplicateResourceError is returned if a
+// resource is received twice.
+func (s *VehicleLockStatusService) GetVehicleLockStatusSnapshot(ctx context.Context, opts *Options) (*Snapshot[VehicleLockStatus], *Response, error) {
+	status, resp, err := s.GetVehicleLockStatus(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	snapshot, err := newSnapshot(status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return snapshot, resp, nil
+}
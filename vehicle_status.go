@@ -2,7 +2,6 @@ package merche
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 )
 
@@ -26,6 +25,86 @@ type VehicleStatus struct {
 	Windowstatusrearright  *Resource `json:"windowstatusrearright,omitempty"`
 }
 
+// DecklidUnlocked reports whether the deck lid is unlocked.
+func (s *VehicleStatus) DecklidUnlocked() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorlockstatusdecklid)
+}
+
+// DoorFrontLeftOpen reports whether the front left door is open.
+func (s *VehicleStatus) DoorFrontLeftOpen() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorstatusfrontleft)
+}
+
+// DoorFrontRightOpen reports whether the front right door is open.
+func (s *VehicleStatus) DoorFrontRightOpen() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorstatusfrontright)
+}
+
+// DoorRearLeftOpen reports whether the rear left door is open.
+func (s *VehicleStatus) DoorRearLeftOpen() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorstatusrearleft)
+}
+
+// DoorRearRightOpen reports whether the rear right door is open.
+func (s *VehicleStatus) DoorRearRightOpen() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.Doorstatusrearright)
+}
+
+// InteriorLightsFrontOn reports whether the front interior lights are on.
+func (s *VehicleStatus) InteriorLightsFrontOn() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.InteriorLightsFront)
+}
+
+// InteriorLightsRearOn reports whether the rear interior lights are on.
+func (s *VehicleStatus) InteriorLightsRearOn() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.InteriorLightsRear)
+}
+
+// ReadingLampFrontLeftOn reports whether the front left reading lamp is on.
+func (s *VehicleStatus) ReadingLampFrontLeftOn() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.ReadingLampFrontLeft)
+}
+
+// ReadingLampFrontRightOn reports whether the front right reading lamp is on.
+func (s *VehicleStatus) ReadingLampFrontRightOn() (*TypedResource[bool], error) {
+	return ParseResource[bool](s.ReadingLampFrontRight)
+}
+
+// LightSwitch returns the position of the light switch.
+func (s *VehicleStatus) LightSwitch() (*TypedResource[LightSwitchPosition], error) {
+	return ParseResource[LightSwitchPosition](s.Lightswitchposition)
+}
+
+// Rooftop returns the status of the convertible top.
+func (s *VehicleStatus) Rooftop() (*TypedResource[RooftopStatus], error) {
+	return ParseResource[RooftopStatus](s.Rooftopstatus)
+}
+
+// Sunroof returns the status of the sunroof.
+func (s *VehicleStatus) Sunroof() (*TypedResource[SunroofStatus], error) {
+	return ParseResource[SunroofStatus](s.Sunroofstatus)
+}
+
+// WindowFrontLeft returns the status of the front left window.
+func (s *VehicleStatus) WindowFrontLeft() (*TypedResource[WindowStatus], error) {
+	return ParseResource[WindowStatus](s.Windowstatusfrontleft)
+}
+
+// WindowFrontRight returns the status of the front right window.
+func (s *VehicleStatus) WindowFrontRight() (*TypedResource[WindowStatus], error) {
+	return ParseResource[WindowStatus](s.Windowstatusfrontright)
+}
+
+// WindowRearLeft returns the status of the rear left window.
+func (s *VehicleStatus) WindowRearLeft() (*TypedResource[WindowStatus], error) {
+	return ParseResource[WindowStatus](s.Windowstatusrearleft)
+}
+
+// WindowRearRight returns the status of the rear right window.
+func (s *VehicleStatus) WindowRearRight() (*TypedResource[WindowStatus], error) {
+	return ParseResource[WindowStatus](s.Windowstatusrearright)
+}
+
 // VehicleStatusService handles communication with vehicle status related
 // methods of the Mercedes API.
 //
@@ -39,7 +118,10 @@ type VehicleStatusService service
 //
 // Mercedes API docs: https://developer.mercedes-benz.com/products/vehicle_status/docs#_3_get_all_values_of_the_vehicle_status_api
 func (s *VehicleStatusService) GetVehicleStatus(ctx context.Context, opts *Options) ([]*VehicleStatus, *Response, error) {
-	path := fmt.Sprintf("%v/%v/containers/vehiclestatus", apiPathPrefix, opts.VehicleID)
+	path, err := s.client.vehiclePath(opts, "containers", string(ContainerVehicleStatus))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
@@ -54,3 +136,21 @@ func (s *VehicleStatusService) GetVehicleStatus(ctx context.Context, opts *Optio
 
 	return status, resp, nil
 }
+
+// GetVehicleStatusSnapshot gets the vehicle status of a vehicle like
+// GetVehicleStatus, folding all the resources of the container into a single
+// VehicleStatus. A *DuplicateResourceError is returned if a resource is
+// received twice.
+func (s *VehicleStatusService) GetVehicleStatusSnapshot(ctx context.Context, opts *Options) (*Snapshot[VehicleStatus], *Response, error) {
+	status, resp, err := s.GetVehicleStatus(ctx, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	snapshot, err := newSnapshot(status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return snapshot, resp, nil
+}
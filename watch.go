@@ -0,0 +1,136 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WatchOptions configures Client.Watch.
+type WatchOptions struct {
+	VehicleID string
+	// Containers are the containers polled, in order.
+	Containers []Container
+	// Interval is the time between the start of two polls.
+	Interval time.Duration
+	// Schedule, if set, decides when to poll instead of Interval.
+	Schedule Scheduler
+}
+
+// WatchEvent is an event emitted by Client.Watch. Either Change or Err is
+// set.
+type WatchEvent struct {
+	VehicleID string
+	Container Container
+	// Time is when the poll that produced the event completed.
+	Time time.Time
+
+	Change *ResourceChange
+	// Err is the error of a failed poll. Polling goes on after errors.
+	Err error
+}
+
+// Watch polls the containers of a vehicle every opts.Interval, or when
+// opts.Schedule decides, and emits a WatchEvent for every resource whose
+// value or timestamp changed since the previous poll. The first poll of
+// each container only sets the baseline.
+//
+// Failed polls are emitted as events with Err set, and the next poll is
+// compared against the last successful one. The channel is closed once ctx
+// is done.
+func (c *Client) Watch(ctx context.Context, opts *WatchOptions) (<-chan WatchEvent, error) {
+	if opts == nil {
+		return nil, &ValidationError{Err: ErrNilOptions}
+	}
+	if err := (&Options{VehicleID: opts.VehicleID}).Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Schedule == nil && opts.Interval <= 0 {
+		return nil, &ValidationError{Field: "Interval", Err: fmt.Errorf("interval must be positive, got %v", opts.Interval)}
+	}
+	if len(opts.Containers) == 0 {
+		return nil, &ValidationError{Field: "Containers", Err: errors.New("no container to watch")}
+	}
+	for _, ct := range opts.Containers {
+		if ct.Scope() == "" {
+			return nil, &ValidationError{Field: "Containers", Err: fmt.Errorf("unknown container %q", ct)}
+		}
+	}
+
+	o := *opts
+	o.Containers = append([]Container(nil), opts.Containers...)
+
+	events := make(chan WatchEvent)
+	go c.watch(ctx, &o, events)
+	return events, nil
+}
+
+func (c *Client) watch(ctx context.Context, o *WatchOptions, events chan<- WatchEvent) {
+	defer close(events)
+
+	send := func(e WatchEvent) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wait time.Duration
+	if o.Schedule != nil {
+		wait = o.Schedule.Next(o.VehicleID).Wait
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	last := make(map[Container]map[string]*Resource)
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+		start := time.Now()
+
+		var requests int
+		var changes []ResourceChange
+		for _, ct := range o.Containers {
+			resources, resp, err := c.getContainer(ctx, &Options{VehicleID: o.VehicleID}, ct)
+			if ctx.Err() != nil {
+				return
+			}
+			if resp != nil {
+				requests += resp.Attempts
+			}
+
+			event := WatchEvent{VehicleID: o.VehicleID, Container: ct, Time: time.Now()}
+			if err != nil {
+				event.Err = err
+				if !send(event) {
+					return
+				}
+				continue
+			}
+
+			if previous, ok := last[ct]; ok {
+				for _, change := range diffResources(previous, resources) {
+					changes = append(changes, change)
+					change := change
+					event.Change = &change
+					if !send(event) {
+						return
+					}
+				}
+			}
+			last[ct] = resources
+		}
+
+		if o.Schedule != nil {
+			timer.Reset(o.Schedule.Observe(o.VehicleID, requests, changes).Wait)
+		} else {
+			timer.Reset(o.Interval - time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package merche
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Watch(t *testing.T) {
+	responses := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusOK, `[{"tanklevelpercent":{"value":"84","timestamp":1}},{"rangeliquid":{"value":"700","timestamp":1}}]`},
+		{http.StatusOK, `[{"tanklevelpercent":{"value":"84","timestamp":1}},{"rangeliquid":{"value":"700","timestamp":1}}]`},
+		{http.StatusTooManyRequests, ``},
+		{http.StatusOK, `[{"tanklevelpercent":{"value":"83","timestamp":2}}]`},
+	}
+
+	var mu sync.Mutex
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		res := responses[len(responses)-1]
+		if polls < len(responses) {
+			res = responses[polls]
+		}
+		polls++
+		w.WriteHeader(res.status)
+		w.Write([]byte(res.body))
+	}))
+	defer server.Close()
+
+	c, err := New(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := c.Watch(ctx, &WatchOptions{
+		VehicleID:  fakeVehicleID,
+		Containers: []Container{ContainerFuelStatus},
+		Interval:   time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	var rateErr *RateLimitError
+	e := <-events
+	assert.True(t, errors.As(e.Err, &rateErr))
+	assert.Equal(t, ContainerFuelStatus, e.Container)
+
+	e = <-events
+	if assert.NotNil(t, e.Change) {
+		assert.Equal(t, fakeVehicleID, e.VehicleID)
+		assert.Equal(t, "rangeliquid", e.Change.Resource)
+		assert.Equal(t, "700", *e.Change.Old.Value)
+		assert.Nil(t, e.Change.New)
+	}
+
+	e = <-events
+	if assert.NotNil(t, e.Change) {
+		assert.Equal(t, "tanklevelpercent", e.Change.Resource)
+		assert.Equal(t, "84", *e.Change.Old.Value)
+		assert.Equal(t, "83", *e.Change.New.Value)
+		assert.Equal(t, int64(2), *e.Change.New.Timestamp)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_Watch_validation(t *testing.T) {
+	c := NewClient(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts *WatchOptions
+	}{
+		{name: "nil options"},
+		{name: "invalid VIN", opts: &WatchOptions{VehicleID: "123", Containers: Containers(), Interval: time.Minute}},
+		{name: "no interval", opts: &WatchOptions{VehicleID: fakeVehicleID, Containers: Containers()}},
+		{name: "no containers", opts: &WatchOptions{VehicleID: fakeVehicleID, Interval: time.Minute}},
+		{name: "unknown container", opts: &WatchOptions{VehicleID: fakeVehicleID, Containers: []Container{"tires"}, Interval: time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Watch(ctx, tt.opts)
+			var validationErr *ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+		})
+	}
+}